@@ -0,0 +1,112 @@
+package simpleforce
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AggregateResult is a row of the results of an aggregate query, e.g. one with GROUP BY, COUNT(Id) or SUM(Amount).
+// Aggregates selected without an alias are named expr0, expr1 and so on, in the order they were selected, e.g. for
+// "SELECT StageName, SUM(Amount), COUNT(Id) cnt FROM Opportunity GROUP BY StageName" the values are named StageName,
+// expr0 and cnt. The accessors look up aliases ignoring case, like SOQL does.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_groupby_alias.htm
+type AggregateResult struct {
+	SObject
+}
+
+// Value returns the value of alias, or nil if there is none.
+func (row *AggregateResult) Value(alias string) interface{} {
+	return row.InterfaceField(row.key(alias))
+}
+
+// String returns the value of alias as a string, or an empty string if it is not a string.
+func (row *AggregateResult) String(alias string) string {
+	return row.StringField(row.key(alias))
+}
+
+// Int returns the value of alias as an integer, e.g. the result of COUNT(Id).
+func (row *AggregateResult) Int(alias string) (int64, error) {
+	return row.IntField(row.key(alias))
+}
+
+// Float returns the value of alias as a float64, e.g. the result of AVG(Amount).
+func (row *AggregateResult) Float(alias string) (float64, error) {
+	return row.FloatField(row.key(alias))
+}
+
+// Decimal returns the value of alias as an exact decimal, e.g. the result of SUM(Amount).
+func (row *AggregateResult) Decimal(alias string) (*big.Rat, error) {
+	return row.DecimalField(row.key(alias))
+}
+
+// key returns the name of the value with the alias, ignoring case.
+func (row *AggregateResult) key(alias string) string {
+	if _, ok := row.SObject[alias]; ok {
+		return alias
+	}
+	for key := range row.SObject {
+		if strings.EqualFold(key, alias) {
+			return key
+		}
+	}
+	return alias
+}
+
+// AggregateResults returns the records of the result as aggregate rows.
+func (result *QueryResult) AggregateResults() []AggregateResult {
+	rows := make([]AggregateResult, 0, len(result.Records))
+	for _, record := range result.Records {
+		rows = append(rows, AggregateResult{record})
+	}
+	return rows
+}
+
+// Aggregate runs an aggregate SOQL query and returns its rows. Aggregate queries are not paginated; Salesforce
+// returns an error if they match more than 2000 rows.
+func (client *Client) Aggregate(q string, opts ...CallOption) ([]AggregateResult, error) {
+	result, err := client.Query(q, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result.AggregateResults(), nil
+}
+
+// countQuery matches the SELECT clause of the queries accepted by Count: "SELECT COUNT() FROM ..." and
+// "SELECT COUNT(field) [alias] FROM ...".
+var countQuery = regexp.MustCompile(`(?i)^\s*SELECT\s+COUNT\(\s*([\w.]*)\s*\)(?:\s+(\w+))?\s+FROM\s`)
+
+// groupByClause matches the GROUP BY clause of a query.
+var groupByClause = regexp.MustCompile(`(?i)\bGROUP\s+BY\b`)
+
+// Count runs a "SELECT COUNT() FROM ..." or "SELECT COUNT(field) FROM ..." SOQL query and returns the count. Any other
+// query, including one with GROUP BY, returns an error without being sent; use Aggregate for those.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_count.htm
+func (client *Client) Count(q string, opts ...CallOption) (int, error) {
+	match := countQuery.FindStringSubmatch(q)
+	if match == nil || groupByClause.MatchString(q) {
+		return 0, errors.Errorf("not a COUNT() or COUNT(field) query without GROUP BY: %s", q)
+	}
+
+	result, err := client.Query(q, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	// Salesforce returns the result of COUNT() as the total size, and that of COUNT(field) as an aggregate.
+	if match[1] == "" {
+		return result.TotalSize, nil
+	}
+	rows := result.AggregateResults()
+	if len(rows) != 1 {
+		return 0, errors.Errorf("expected a single aggregate result, got %d", len(rows))
+	}
+	alias := match[2]
+	if alias == "" {
+		alias = "expr0"
+	}
+	count, err := rows[0].Int(alias)
+	return int(count), err
+}
@@ -0,0 +1,73 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Aggregate(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"totalSize":2,"done":true,"records":[
+			{"attributes":{"type":"AggregateResult"},"StageName":"Closed Won","expr0":1234567.89,"Cnt":12},
+			{"attributes":{"type":"AggregateResult"},"StageName":"Prospecting","expr0":null,"Cnt":3}
+		]}`)
+	})
+
+	rows, err := client.Aggregate("SELECT StageName, SUM(Amount), COUNT(Id) cnt FROM Opportunity GROUP BY StageName")
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("unexpected rows %v, %v", rows, err)
+	}
+	if stage := rows[0].String("StageName"); stage != "Closed Won" {
+		t.Errorf("unexpected stage %s", stage)
+	}
+	if count, err := rows[0].Int("cnt"); err != nil || count != 12 {
+		t.Errorf("unexpected count %v, %v", count, err)
+	}
+	if sum, err := rows[0].Decimal("EXPR0"); err != nil || sum.FloatString(2) != "1234567.89" {
+		t.Errorf("unexpected sum %v, %v", sum, err)
+	}
+	if _, err := rows[1].Float("expr0"); err == nil {
+		t.Error("expected an error for a null aggregate")
+	}
+}
+
+func TestClient_Count(t *testing.T) {
+	responses := map[string]string{
+		"SELECT COUNT() FROM Account":         `{"totalSize":42,"done":true,"records":[]}`,
+		"SELECT COUNT(Id) FROM Account":       `{"totalSize":1,"done":true,"records":[{"attributes":{"type":"AggregateResult"},"expr0":41}]}`,
+		"select count(Id) total from Account": `{"totalSize":1,"done":true,"records":[{"attributes":{"type":"AggregateResult"},"total":7}]}`,
+	}
+	var queries []string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		queries = append(queries, q)
+		fmt.Fprint(w, responses[q])
+	})
+
+	for q, expected := range map[string]int{
+		"SELECT COUNT() FROM Account":         42,
+		"SELECT COUNT(Id) FROM Account":       41,
+		"select count(Id) total from Account": 7,
+	} {
+		if count, err := client.Count(q); err != nil || count != expected {
+			t.Errorf("%s: expected %d, got %d, %v", q, expected, count, err)
+		}
+	}
+
+	// Other queries are rejected without being sent.
+	queries = nil
+	for _, q := range []string{
+		"SELECT Id FROM Account",
+		"SELECT MAX(Amount) FROM Opportunity",
+		"SELECT COUNT(Id) FROM Opportunity GROUP BY StageName",
+		"SELECT COUNT(Id), MAX(Amount) FROM Opportunity",
+	} {
+		if _, err := client.Count(q); err == nil {
+			t.Errorf("%s: expected an error", q)
+		}
+	}
+	if len(queries) != 0 {
+		t.Errorf("unexpected queries %v", queries)
+	}
+}
@@ -0,0 +1,96 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CallOption sets a header of a single call to the REST API, e.g. Query or the Create of an SObject. Use WithHeader to
+// send a header with every call instead.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers.htm
+type CallOption func(header http.Header)
+
+// CallHeader sets an arbitrary header of the call.
+func CallHeader(key, value string) CallOption {
+	return func(header http.Header) {
+		header.Set(key, value)
+	}
+}
+
+// AutoAssign sets whether the active assignment rules are applied when creating or updating cases or leads.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_autoassign.htm
+func AutoAssign(enabled bool) CallOption {
+	return CallHeader("Sforce-Auto-Assign", strings.ToUpper(fmt.Sprint(enabled)))
+}
+
+// AssignmentRule applies the assignment rule with the given ID when creating or updating cases or leads.
+func AssignmentRule(ruleID string) CallOption {
+	return CallHeader("Sforce-Auto-Assign", ruleID)
+}
+
+// CallOptions identifies the client making the call and sets the namespace prefix of the call, allowing fields of a
+// managed package to be referenced without their prefix. Empty values are omitted.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_calloptions.htm
+func CallOptions(client, defaultNamespace string) CallOption {
+	var options []string
+	if client != "" {
+		options = append(options, "client="+client)
+	}
+	if defaultNamespace != "" {
+		options = append(options, "defaultNamespace="+defaultNamespace)
+	}
+	return CallHeader("Sforce-Call-Options", strings.Join(options, ", "))
+}
+
+// QueryBatchSize sets the number of records returned per page of query results, between 200 and 2000. Salesforce may
+// return fewer records if it is more efficient.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_queryoptions.htm
+func QueryBatchSize(batchSize int) CallOption {
+	return CallHeader("Sforce-Query-Options", fmt.Sprintf("batchSize=%d", batchSize))
+}
+
+// UpdateMRU sets whether the call adds the records it accesses to the most recently used items of the user.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_mru.htm
+func UpdateMRU(enabled bool) CallOption {
+	return CallHeader("Sforce-Mru", fmt.Sprintf("updateMru=%t", enabled))
+}
+
+// callHeader returns the header set by opts.
+func callHeader(opts []CallOption) http.Header {
+	header := http.Header{}
+	for _, opt := range opts {
+		opt(header)
+	}
+	return header
+}
+
+// WithCallOptions sets options of the next Get, Create, Update, Upsert or Delete of the SObject. The same SObject
+// pointer is returned to allow chained access, e.g.
+// client.SObject("Case").Set("Subject", "Help").WithCallOptions(AutoAssign(true)).Create().
+func (obj *SObject) WithCallOptions(opts ...CallOption) *SObject {
+	header, _ := (*obj)[sobjectCallOptionsKey].(http.Header)
+	if header == nil {
+		header = http.Header{}
+	}
+	for _, opt := range opts {
+		opt(header)
+	}
+	(*obj)[sobjectCallOptionsKey] = header
+	return obj
+}
+
+// requestHeader adds the headers set by WithCallOptions to header, and clears them, as they only apply to a single
+// request.
+func (obj *SObject) requestHeader(header http.Header) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	if callHeader, ok := (*obj)[sobjectCallOptionsKey].(http.Header); ok {
+		for key, values := range callHeader {
+			header[key] = values
+		}
+		delete(*obj, sobjectCallOptionsKey)
+	}
+	return header
+}
@@ -0,0 +1,70 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_QueryCallOptions(t *testing.T) {
+	var header http.Header
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		fmt.Fprint(w, `{"totalSize":0,"done":true,"records":[]}`)
+	})
+
+	_, err := client.Query("SELECT Id FROM Account", QueryBatchSize(500), UpdateMRU(true), CallOptions("acme/1.0", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{
+		"Sforce-Query-Options": "batchSize=500",
+		"Sforce-Mru":           "updateMru=true",
+		"Sforce-Call-Options":  "client=acme/1.0",
+	} {
+		if value := header.Get(key); value != expected {
+			t.Errorf("expected %s: %s, got %q", key, expected, value)
+		}
+	}
+}
+
+func TestSObject_WithCallOptions(t *testing.T) {
+	var headers []http.Header
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"500000000000001AAA","success":true,"errors":[]}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	obj := client.SObject("Case").
+		Set("Subject", "Help").
+		WithCallOptions(AutoAssign(true), CallOptions("acme/1.0", "acme")).
+		WithCallOptions(AssignmentRule("01Q000000000001AAA")).
+		Create()
+	if obj == nil {
+		t.Fatal("failed to create case")
+	}
+	if _, ok := obj.makeCopy()[sobjectCallOptionsKey]; ok {
+		t.Error("expected call options not to be sent as a field")
+	}
+	if obj.Set("Subject", "More help").Update() == nil {
+		t.Fatalf("failed to update case, %v", obj.Err())
+	}
+
+	if len(headers) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(headers))
+	}
+	if value := headers[0].Get("Sforce-Auto-Assign"); value != "01Q000000000001AAA" {
+		t.Errorf("unexpected Sforce-Auto-Assign %q", value)
+	}
+	if value := headers[0].Get("Sforce-Call-Options"); value != "client=acme/1.0, defaultNamespace=acme" {
+		t.Errorf("unexpected Sforce-Call-Options %q", value)
+	}
+	if headers[1].Get("Sforce-Auto-Assign") != "" || headers[1].Get("Sforce-Call-Options") != "" {
+		t.Error("expected call options to apply to a single request")
+	}
+}
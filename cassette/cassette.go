@@ -0,0 +1,324 @@
+// Package cassette records the HTTP interactions of a simpleforce client with Salesforce to fixture files, and replays
+// them offline, so that code using simpleforce can be tested deterministically without an org.
+//
+// Session IDs, access tokens and passwords are redacted before interactions are saved, so fixtures can be committed:
+//
+//	recorder, err := cassette.New("testdata/accounts.json", cassette.ModeAuto)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer recorder.Stop()
+//
+//	client := simpleforce.NewClient(sfURL, simpleforce.DefaultClientID, simpleforce.DefaultAPIVersion)
+//	client.SetHttpClient(recorder.HTTPClient())
+//
+// Requests are matched to recorded interactions by method, URL and redacted body, in the order they were recorded.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Redacted replaces secrets in recorded interactions.
+const Redacted = "REDACTED"
+
+// Mode selects whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay replays the interactions of an existing cassette and fails requests that have not been recorded.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to Salesforce and records the interactions, replacing the cassette when stopped.
+	ModeRecord
+	// ModeAuto replays the cassette if it exists and records it otherwise.
+	ModeAuto
+)
+
+var (
+	// ErrInteractionNotFound is returned in replay mode for requests that have not been recorded.
+	ErrInteractionNotFound = errors.New("cassette: interaction not found")
+)
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	replayed bool
+}
+
+// Body is the body of a recorded request or response. It is saved as a string if it is valid UTF-8, and base64
+// encoded otherwise.
+type Body []byte
+
+// MarshalJSON implements json.Marshaler.
+func (body Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(body) {
+		return json.Marshal(string(body))
+	}
+	return json.Marshal(map[string][]byte{"base64": body})
+}
+
+// String returns the body as a string, e.g. for use in redactors.
+func (body Body) String() string {
+	return string(body)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (body *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*body = Body(s)
+		return nil
+	}
+	var encoded map[string][]byte
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	*body = encoded["base64"]
+	return nil
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithTransport sets the transport used to send requests in record mode. It defaults to http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(recorder *Recorder) {
+		recorder.transport = transport
+	}
+}
+
+// WithRedactor adds a function that redacts interactions before they are recorded, in addition to the built-in
+// redaction of session IDs, tokens and passwords. It is also applied to requests before they are matched in replay
+// mode, so it must redact recorded and live requests alike.
+func WithRedactor(redact func(*Interaction)) Option {
+	return func(recorder *Recorder) {
+		recorder.redactors = append(recorder.redactors, redact)
+	}
+}
+
+// Recorder is an http.RoundTripper that records or replays the interactions of a cassette file.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	redactors []func(*Interaction)
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// New creates a Recorder for the cassette at path. In replay mode, the cassette is loaded immediately.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	recorder := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		redactors: []func(*Interaction){redactSecrets},
+	}
+	for _, opt := range opts {
+		opt(recorder)
+	}
+
+	if recorder.mode == ModeAuto {
+		recorder.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			recorder.mode = ModeReplay
+		}
+	}
+	if recorder.mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &recorder.interactions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cassette %s", path)
+		}
+	}
+	return recorder, nil
+}
+
+// Mode returns whether the recorder records or replays interactions. It never returns ModeAuto.
+func (recorder *Recorder) Mode() Mode {
+	return recorder.mode
+}
+
+// HTTPClient returns an HTTP client sending its requests through the recorder, to be passed to
+// simpleforce.Client.SetHttpClient.
+func (recorder *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: recorder}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (recorder *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	interaction := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   body,
+		},
+	}
+
+	if recorder.mode == ModeReplay {
+		recorder.redact(interaction)
+		return recorder.replay(req, interaction.Request)
+	}
+
+	resp, err := recorder.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction.Response = Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       respBody,
+	}
+	recorder.redact(interaction)
+
+	recorder.mu.Lock()
+	recorder.interactions = append(recorder.interactions, interaction)
+	recorder.mu.Unlock()
+	return resp, nil
+}
+
+// Stop saves the recorded interactions to the cassette file in record mode. It does nothing in replay mode.
+func (recorder *Recorder) Stop() error {
+	if recorder.mode != ModeRecord {
+		return nil
+	}
+
+	recorder.mu.Lock()
+	data, err := json.MarshalIndent(recorder.interactions, "", "  ")
+	recorder.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(recorder.path), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(recorder.path, data, 0644)
+}
+
+// replay returns the response of the first interaction matching the request that has not been replayed yet.
+func (recorder *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	for _, interaction := range recorder.interactions {
+		if interaction.replayed || !matches(interaction.Request, recorded) {
+			continue
+		}
+		interaction.replayed = true
+
+		resp := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			StatusCode:    resp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        resp.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, errors.Wrapf(ErrInteractionNotFound, "%s %s", recorded.Method, recorded.URL)
+}
+
+// matches reports whether a live request matches a recorded one.
+func matches(recorded, live Request) bool {
+	return recorded.Method == live.Method && recorded.URL == live.URL && bytes.Equal(recorded.Body, live.Body)
+}
+
+// redact applies the redactors of the recorder to interaction.
+func (recorder *Recorder) redact(interaction *Interaction) {
+	for _, redact := range recorder.redactors {
+		redact(interaction)
+	}
+}
+
+var (
+	// secretHeaders are removed from recorded requests and responses.
+	secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+	// secretElements matches the SOAP elements holding the password and session ID of a login.
+	secretElements = regexp.MustCompile(`(<(?:\w+:)?(?:password|sessionId)>)[^<]*(</(?:\w+:)?(?:password|sessionId)>)`)
+
+	// secretParameters matches the tokens, secrets and passwords of OAuth requests and responses, either as JSON
+	// properties or as form parameters.
+	secretParameters = regexp.MustCompile(`("(?:access_token|refresh_token|id_token|client_secret|password)"\s*:\s*")[^"]*(")|` +
+		`\b((?:access_token|refresh_token|id_token|client_secret|password)=)[^&\s]*()`)
+)
+
+// redactSecrets removes session IDs, tokens and passwords from an interaction.
+func redactSecrets(interaction *Interaction) {
+	for _, key := range secretHeaders {
+		interaction.Request.Header.Del(key)
+		interaction.Response.Header.Del(key)
+	}
+	interaction.Request.Body = redactBody(interaction.Request.Body)
+	interaction.Response.Body = redactBody(interaction.Response.Body)
+}
+
+// redactBody replaces the secrets in body with Redacted.
+func redactBody(body Body) Body {
+	if len(body) == 0 {
+		return body
+	}
+	body = secretElements.ReplaceAll(body, []byte("${1}"+Redacted+"${2}"))
+	return secretParameters.ReplaceAllFunc(body, func(match []byte) []byte {
+		parts := secretParameters.FindSubmatch(match)
+		if parts[1] != nil {
+			return []byte(string(parts[1]) + Redacted + string(parts[2]))
+		}
+		return []byte(string(parts[3]) + Redacted)
+	})
+}
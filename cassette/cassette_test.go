@@ -0,0 +1,135 @@
+package cassette
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/simpleforce/simpleforce"
+)
+
+const testSessionID = "00D000000000001!SECRET_SESSION"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/services/Soap/") {
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><loginResponse><result>
+<serverUrl>%s/services/Soap/u/54.0/00D000000000001</serverUrl><sessionId>%s</sessionId>
+<userId>005000000000001</userId><userInfo><userName>user@example.com</userName></userInfo>
+</result></loginResponse></soapenv:Body></soapenv:Envelope>`, server.URL, testSessionID)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+testSessionID {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `[{"message":"Session expired or invalid","errorCode":"INVALID_SESSION_ID"}]`)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: testSessionID})
+		fmt.Fprint(w, `{"totalSize":1,"done":true,"records":[{"attributes":{"type":"Account"},"Id":"001","Name":"Acme"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func queryAccounts(t *testing.T, url string, httpClient *http.Client) *simpleforce.QueryResult {
+	client := simpleforce.NewClient(url, simpleforce.DefaultClientID, simpleforce.DefaultAPIVersion)
+	client.SetHttpClient(httpClient)
+	if err := client.LoginPassword("user@example.com", "hunter2", "TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Query("SELECT Id, Name FROM Account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "accounts.json")
+	server := newTestServer(t)
+
+	recorder, err := New(path, ModeAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Mode() != ModeRecord {
+		t.Fatalf("expected record mode for a missing cassette, got %v", recorder.Mode())
+	}
+	result := queryAccounts(t, server.URL, recorder.HTTPClient())
+	if len(result.Records) != 1 {
+		t.Fatalf("unexpected records %v", result.Records)
+	}
+	if err = recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	url := server.URL
+	server.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"SECRET_SESSION", "hunter2", "TOKEN", "Authorization", "Set-Cookie"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %s:\n%s", secret, data)
+		}
+	}
+
+	recorder, err = New(path, ModeAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Mode() != ModeReplay {
+		t.Fatalf("expected replay mode for an existing cassette, got %v", recorder.Mode())
+	}
+	result = queryAccounts(t, url, recorder.HTTPClient())
+	if len(result.Records) != 1 || result.Records[0].StringField("Name") != "Acme" {
+		t.Errorf("unexpected replayed records %v", result.Records)
+	}
+
+	_, err = recorder.HTTPClient().Get(url + "/services/data/v54.0/limits/")
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("expected ErrInteractionNotFound, got %v", err)
+	}
+}
+
+func TestBody_JSON(t *testing.T) {
+	for _, body := range []Body{Body("text"), Body{0xff, 0xfe, 0x00}} {
+		data, err := body.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Body
+		if err = decoded.UnmarshalJSON(data); err != nil || string(decoded) != string(body) {
+			t.Errorf("unexpected round trip of %q: %q, %v", body, decoded, err)
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	interaction := &Interaction{
+		Request: Request{
+			Header: http.Header{"Authorization": {"Bearer token"}},
+			Body:   Body("grant_type=password&client_secret=s3cret&password=hunter2"),
+		},
+		Response: Response{Body: Body(`{"access_token": "abc", "instance_url": "https://acme.my.salesforce.com"}`)},
+	}
+	redactSecrets(interaction)
+
+	if interaction.Request.Header.Get("Authorization") != "" {
+		t.Error("expected the Authorization header to be removed")
+	}
+	if body := interaction.Request.Body.String(); body != "grant_type=password&client_secret=REDACTED&password=REDACTED" {
+		t.Errorf("unexpected request body %s", body)
+	}
+	if body := interaction.Response.Body.String(); body != `{"access_token": "REDACTED", "instance_url": "https://acme.my.salesforce.com"}` {
+		t.Errorf("unexpected response body %s", body)
+	}
+}
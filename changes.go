@@ -0,0 +1,247 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// changesDateLayout is the layout of the start and end parameters of the updated and deleted resources.
+	changesDateLayout = "2006-01-02T15:04:05-07:00"
+
+	// initialSyncWindow is how far back SyncChanges looks if no watermark has been saved yet. Salesforce only keeps
+	// 30 days of change history.
+	initialSyncWindow = 29 * 24 * time.Hour
+)
+
+// UpdatedResult holds the IDs of the records updated within a date range.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_getupdated.htm
+type UpdatedResult struct {
+	IDs               []string
+	LatestDateCovered time.Time
+}
+
+// DeletedRecord describes a record deleted within a date range.
+type DeletedRecord struct {
+	ID          string
+	DeletedDate time.Time
+}
+
+// DeletedResult holds the records deleted within a date range.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_getdeleted.htm
+type DeletedResult struct {
+	DeletedRecords        []DeletedRecord
+	EarliestDateAvailable time.Time
+	LatestDateCovered     time.Time
+}
+
+// GetUpdated returns the IDs of the records of sobjectType updated between start and end. Salesforce rounds both dates
+// down to the minute and only keeps 30 days of history.
+func (client *Client) GetUpdated(sobjectType string, start, end time.Time) (*UpdatedResult, error) {
+	var resp struct {
+		IDs               []string `json:"ids"`
+		LatestDateCovered string   `json:"latestDateCovered"`
+	}
+	err := client.getChanges(sobjectType, "updated", start, end, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UpdatedResult{IDs: resp.IDs}
+	result.LatestDateCovered, err = time.Parse(DateTimeLayout, resp.LatestDateCovered)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDeleted returns the records of sobjectType deleted between start and end.
+func (client *Client) GetDeleted(sobjectType string, start, end time.Time) (*DeletedResult, error) {
+	var resp struct {
+		DeletedRecords []struct {
+			ID          string `json:"id"`
+			DeletedDate string `json:"deletedDate"`
+		} `json:"deletedRecords"`
+		EarliestDateAvailable string `json:"earliestDateAvailable"`
+		LatestDateCovered     string `json:"latestDateCovered"`
+	}
+	err := client.getChanges(sobjectType, "deleted", start, end, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeletedResult{}
+	for _, record := range resp.DeletedRecords {
+		deletedDate, err := time.Parse(DateTimeLayout, record.DeletedDate)
+		if err != nil {
+			return nil, err
+		}
+		result.DeletedRecords = append(result.DeletedRecords, DeletedRecord{ID: record.ID, DeletedDate: deletedDate})
+	}
+	result.EarliestDateAvailable, err = time.Parse(DateTimeLayout, resp.EarliestDateAvailable)
+	if err != nil {
+		return nil, err
+	}
+	result.LatestDateCovered, err = time.Parse(DateTimeLayout, resp.LatestDateCovered)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getChanges queries the updated or deleted resource of sobjectType and decodes the response into v.
+func (client *Client) getChanges(sobjectType, resource string, start, end time.Time, v interface{}) error {
+	if !client.isLoggedIn() {
+		return ErrAuthentication
+	}
+
+	params := url.Values{}
+	params.Set("start", start.UTC().Format(changesDateLayout))
+	params.Set("end", end.UTC().Format(changesDateLayout))
+	u := client.makeURL(fmt.Sprintf("sobjects/%s/%s/?%s", sobjectType, resource, params.Encode()))
+
+	op := operation{name: "GetUpdated", sobjectType: sobjectType}
+	if resource == "deleted" {
+		op.name = "GetDeleted"
+	}
+	data, err := client.httpRequest(op, http.MethodGet, u, nil)
+	if err != nil {
+		client.logln("HTTP GET request failed:", u)
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// ChangeSet holds the records of an SObject type that changed within a date range.
+type ChangeSet struct {
+	SObjectType string
+	Start       time.Time
+	End         time.Time
+	Updated     []string
+	Deleted     []DeletedRecord
+}
+
+// WatermarkStore persists how far the changes of each SObject type have been synchronized by SyncChanges.
+type WatermarkStore interface {
+	// Watermark returns the saved watermark of sobjectType, or the zero time if none has been saved yet.
+	Watermark(sobjectType string) (time.Time, error)
+	// SaveWatermark saves the watermark of sobjectType.
+	SaveWatermark(sobjectType string, watermark time.Time) error
+}
+
+// SyncChanges fetches the records of sobjectType updated and deleted since the watermark saved in store and passes
+// them to handle. Once handle succeeds, the latest date covered by Salesforce is saved as the new watermark, so that a
+// failed sync is retried from the same point. If no watermark has been saved yet, the changes of the past 29 days are
+// fetched, which is about as far back as Salesforce keeps them.
+func (client *Client) SyncChanges(sobjectType string, store WatermarkStore, handle func(*ChangeSet) error) error {
+	start, err := store.Watermark(sobjectType)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	if start.IsZero() {
+		start = end.Add(-initialSyncWindow)
+	}
+
+	updated, err := client.GetUpdated(sobjectType, start, end)
+	if err != nil {
+		return err
+	}
+	deleted, err := client.GetDeleted(sobjectType, start, end)
+	if err != nil {
+		return err
+	}
+
+	err = handle(&ChangeSet{
+		SObjectType: sobjectType,
+		Start:       start,
+		End:         end,
+		Updated:     updated.IDs,
+		Deleted:     deleted.DeletedRecords,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Both resources should cover the same range, but only advance as far as both are known to be complete.
+	watermark := updated.LatestDateCovered
+	if deleted.LatestDateCovered.Before(watermark) {
+		watermark = deleted.LatestDateCovered
+	}
+	return store.SaveWatermark(sobjectType, watermark)
+}
+
+// FileWatermarkStore is a WatermarkStore that keeps the watermarks of all SObject types in a JSON file.
+type FileWatermarkStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Watermark returns the saved watermark of sobjectType.
+func (store *FileWatermarkStore) Watermark(sobjectType string) (time.Time, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	watermarks, err := store.load()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return watermarks[sobjectType], nil
+}
+
+// SaveWatermark saves the watermark of sobjectType. The file is replaced atomically so that a crash never leaves a
+// partially written file behind.
+func (store *FileWatermarkStore) SaveWatermark(sobjectType string, watermark time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	watermarks, err := store.load()
+	if err != nil {
+		return err
+	}
+	watermarks[sobjectType] = watermark
+
+	data, err := json.MarshalIndent(watermarks, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(store.Path), filepath.Base(store.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), store.Path)
+}
+
+// load reads the watermarks from the file. A missing file is treated as empty.
+func (store *FileWatermarkStore) load() (map[string]time.Time, error) {
+	watermarks := make(map[string]time.Time)
+	data, err := ioutil.ReadFile(store.Path)
+	if os.IsNotExist(err) {
+		return watermarks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &watermarks)
+	if err != nil {
+		return nil, err
+	}
+	return watermarks, nil
+}
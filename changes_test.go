@@ -0,0 +1,91 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func changesHandler(t *testing.T, requests *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RequestURI())
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/sobjects/Account/updated/"):
+			fmt.Fprint(w, `{"ids":["001000000000001","001000000000002"],"latestDateCovered":"2022-04-29T10:15:00.000+0000"}`)
+		case strings.HasSuffix(r.URL.Path, "/sobjects/Account/deleted/"):
+			fmt.Fprint(w, `{"deletedRecords":[{"id":"001000000000003","deletedDate":"2022-04-29T09:00:00.000+0000"}],
+				"earliestDateAvailable":"2022-04-01T00:00:00.000+0000","latestDateCovered":"2022-04-29T10:14:00.000+0000"}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestClient_GetUpdatedDeleted(t *testing.T) {
+	var requests []string
+	client := requireTestServer(t, changesHandler(t, &requests))
+
+	start := time.Date(2022, 4, 28, 10, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	updated, err := client.GetUpdated("Account", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.IDs) != 2 || !updated.LatestDateCovered.Equal(time.Date(2022, 4, 29, 10, 15, 0, 0, time.UTC)) {
+		t.Errorf("unexpected result %+v", updated)
+	}
+	if !strings.HasSuffix(requests[0], "?end=2022-04-29T10%3A00%3A00%2B00%3A00&start=2022-04-28T10%3A00%3A00%2B00%3A00") {
+		t.Errorf("unexpected request %s", requests[0])
+	}
+
+	deleted, err := client.GetDeleted("Account", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted.DeletedRecords) != 1 || deleted.DeletedRecords[0].ID != "001000000000003" {
+		t.Errorf("unexpected result %+v", deleted)
+	}
+}
+
+func TestClient_SyncChanges(t *testing.T) {
+	var requests []string
+	client := requireTestServer(t, changesHandler(t, &requests))
+	store := &FileWatermarkStore{Path: filepath.Join(t.TempDir(), "watermarks.json")}
+
+	// A failing handler must not advance the watermark.
+	errHandler := errors.New("handler failed")
+	err := client.SyncChanges("Account", store, func(changes *ChangeSet) error {
+		return errHandler
+	})
+	if err != errHandler {
+		t.Errorf("expected handler error, got %v", err)
+	}
+	if watermark, err := store.Watermark("Account"); err != nil || !watermark.IsZero() {
+		t.Errorf("unexpected watermark %v, %v", watermark, err)
+	}
+
+	var changes *ChangeSet
+	err = client.SyncChanges("Account", store, func(c *ChangeSet) error {
+		changes = c
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Updated) != 2 || len(changes.Deleted) != 1 {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+
+	// The watermark is the earlier of both latest dates covered, and persisted across stores.
+	store = &FileWatermarkStore{Path: store.Path}
+	watermark, err := store.Watermark("Account")
+	if err != nil || !watermark.Equal(time.Date(2022, 4, 29, 10, 14, 0, 0, time.UTC)) {
+		t.Errorf("unexpected watermark %v, %v", watermark, err)
+	}
+}
@@ -0,0 +1,80 @@
+package simpleforce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// QueryLocator returns the query locator of the next page of results, e.g. "01gD0000002HU6KIAW-2000", or an empty
+// string if there are no more pages. See ResumeQuery.
+func (result *QueryResult) QueryLocator() string {
+	if result.NextRecordsURL == "" {
+		return ""
+	}
+	return result.NextRecordsURL[strings.LastIndex(result.NextRecordsURL, "/")+1:]
+}
+
+// QueryCursor iterates over the pages of the results of a query. Its position can be saved with Locator, so that a
+// long running export can be checkpointed and continued with ResumeQuery, e.g. after a crash:
+//
+//	cursor := client.QueryCursor("SELECT Id, Name FROM Account", QueryBatchSize(500))
+//	for cursor.More() {
+//		result, err := cursor.Next()
+//		if err != nil {
+//			return err
+//		}
+//		// Process result.Records, then checkpoint cursor.Locator().
+//	}
+type QueryCursor struct {
+	client *Client
+	opts   []CallOption
+	next   string
+	done   bool
+}
+
+// QueryCursor returns a cursor over the results of the SOQL query q. opts apply to every page, e.g. QueryBatchSize.
+func (client *Client) QueryCursor(q string, opts ...CallOption) *QueryCursor {
+	return &QueryCursor{client: client, opts: opts, next: q}
+}
+
+// ResumeQuery returns a cursor continuing a query from the page identified by locator, as returned by
+// QueryCursor.Locator or QueryResult.QueryLocator. Salesforce keeps query locators for 15 minutes after their last
+// use, and limits the number of open locators per user.
+func (client *Client) ResumeQuery(locator string, opts ...CallOption) *QueryCursor {
+	resource := "query"
+	if client.useToolingAPI {
+		resource = "tooling/query"
+	}
+	next := fmt.Sprintf("/services/data/v%s/%s/%s", client.apiVersion, resource, locator)
+	return &QueryCursor{client: client, opts: opts, next: next, done: locator == ""}
+}
+
+// More reports whether there are more pages to fetch with Next.
+func (cursor *QueryCursor) More() bool {
+	return !cursor.done
+}
+
+// Next fetches the next page of results. If it fails, it can be called again to retry the same page.
+func (cursor *QueryCursor) Next() (*QueryResult, error) {
+	if cursor.done {
+		return nil, errors.New("no more query results")
+	}
+	result, err := cursor.client.Query(cursor.next, cursor.opts...)
+	if err != nil {
+		return nil, err
+	}
+	cursor.next = result.NextRecordsURL
+	cursor.done = result.Done || result.NextRecordsURL == ""
+	return result, nil
+}
+
+// Locator returns the query locator of the next page to fetch, to resume the query with ResumeQuery. It is empty
+// before the first page has been fetched and once all pages have been fetched.
+func (cursor *QueryCursor) Locator() string {
+	if cursor.done || !strings.HasPrefix(cursor.next, "/services/data") {
+		return ""
+	}
+	return cursor.next[strings.LastIndex(cursor.next, "/")+1:]
+}
@@ -0,0 +1,66 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQueryCursor(t *testing.T) {
+	var batchSizes []string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		batchSizes = append(batchSizes, r.Header.Get("Sforce-Query-Options"))
+		switch {
+		case r.URL.Path == "/services/data/v54.0/query":
+			fmt.Fprint(w, `{"totalSize":5,"done":false,"nextRecordsUrl":"/services/data/v54.0/query/01g000000000001-2",
+				"records":[{"Id":"1"},{"Id":"2"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/query/01g000000000001-2"):
+			fmt.Fprint(w, `{"totalSize":5,"done":false,"nextRecordsUrl":"/services/data/v54.0/query/01g000000000001-4",
+				"records":[{"Id":"3"},{"Id":"4"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/query/01g000000000001-4"):
+			fmt.Fprint(w, `{"totalSize":5,"done":true,"records":[{"Id":"5"}]}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"message":"invalid query locator","errorCode":"INVALID_QUERY_LOCATOR"}]`)
+		}
+	})
+
+	cursor := client.QueryCursor("SELECT Id FROM Account", QueryBatchSize(2))
+	if cursor.Locator() != "" || !cursor.More() {
+		t.Fatal("unexpected state of a new cursor")
+	}
+	result, err := cursor.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Records) != 2 || result.QueryLocator() != "01g000000000001-2" || cursor.Locator() != "01g000000000001-2" {
+		t.Errorf("unexpected first page %v, locator %s", result, cursor.Locator())
+	}
+
+	// Resume from the checkpoint, e.g. after a crash.
+	var ids []string
+	cursor = client.ResumeQuery(cursor.Locator(), QueryBatchSize(2))
+	for cursor.More() {
+		result, err := cursor.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range result.Records {
+			ids = append(ids, record.ID())
+		}
+	}
+	if fmt.Sprint(ids) != "[3 4 5]" || cursor.Locator() != "" {
+		t.Errorf("unexpected records %v, locator %s", ids, cursor.Locator())
+	}
+	if _, err = cursor.Next(); err == nil {
+		t.Error("expected an error after the last page")
+	}
+	if fmt.Sprint(batchSizes) != "[batchSize=2 batchSize=2 batchSize=2]" {
+		t.Errorf("unexpected query options %v", batchSizes)
+	}
+
+	if _, err = client.ResumeQuery("01g000000000002-2").Next(); err == nil {
+		t.Error("expected an invalid locator to fail")
+	}
+}
@@ -0,0 +1,112 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DuplicateRuleHeader controls how duplicate rules are applied when a record is saved.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_duplicaterules.htm
+type DuplicateRuleHeader struct {
+	// AllowSave saves the record even if it is a duplicate, as long as the duplicate rule only alerts.
+	AllowSave bool
+	// IncludeRecordDetails includes the fields of the matching records in the DuplicateResult, not only their IDs.
+	IncludeRecordDetails bool
+	// RunAsCurrentUser applies the sharing rules of the current user when looking for duplicates.
+	RunAsCurrentUser bool
+}
+
+// String formats the header as the value of the Sforce-Duplicate-Rule-Header header.
+func (header DuplicateRuleHeader) String() string {
+	return fmt.Sprintf("allowSave=%t, includeRecordDetails=%t, runAsCurrentUser=%t",
+		header.AllowSave, header.IncludeRecordDetails, header.RunAsCurrentUser)
+}
+
+// DuplicateResult describes the duplicates found by a duplicate rule, as returned with DUPLICATES_DETECTED errors.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.apexref.meta/apexref/apex_class_Datacloud_DuplicateResult.htm
+type DuplicateResult struct {
+	AllowSave               bool          `json:"allowSave"`
+	DuplicateRule           string        `json:"duplicateRule"`
+	DuplicateRuleEntityType string        `json:"duplicateRuleEntityType"`
+	ErrorMessage            string        `json:"errorMessage"`
+	MatchResults            []MatchResult `json:"matchResults"`
+}
+
+// MatchResult holds the records matched by a matching rule of a duplicate rule.
+type MatchResult struct {
+	EntityType   string        `json:"entityType"`
+	MatchEngine  string        `json:"matchEngine"`
+	Rule         string        `json:"rule"`
+	Size         int           `json:"size"`
+	Success      bool          `json:"success"`
+	MatchRecords []MatchRecord `json:"matchRecords"`
+}
+
+// MatchRecord is an existing record matched as a duplicate.
+type MatchRecord struct {
+	MatchConfidence float64     `json:"matchConfidence"`
+	FieldDiffs      []FieldDiff `json:"fieldDiffs"`
+	Record          SObject     `json:"record"`
+}
+
+// FieldDiff describes how a field of a matched record compares to the record being saved, e.g. "SAME" or "DIFFERENT".
+type FieldDiff struct {
+	Name       string `json:"name"`
+	Difference string `json:"difference"`
+}
+
+// Records returns the records matched by all matching rules. If the result was returned by a request of a Client, the
+// records are attached to it, so that they can be retrieved or updated.
+func (result *DuplicateResult) Records() []SObject {
+	var records []SObject
+	for _, match := range result.MatchResults {
+		for _, record := range match.MatchRecords {
+			records = append(records, record.Record)
+		}
+	}
+	return records
+}
+
+// DuplicateResult returns the duplicates found by the duplicate rule that blocked saving a record, or nil if the error
+// was not caused by a duplicate rule.
+func (err SalesforceError) DuplicateResult() *DuplicateResult {
+	for _, detail := range err.Errors() {
+		if detail.DuplicateResult != nil {
+			detail.DuplicateResult.setClient(err.client)
+			return detail.DuplicateResult
+		}
+	}
+	return nil
+}
+
+// setClient attaches client to the matched records.
+func (result *DuplicateResult) setClient(client *Client) {
+	if client == nil {
+		return
+	}
+	for i := range result.MatchResults {
+		for j := range result.MatchResults[i].MatchRecords {
+			if record := result.MatchResults[i].MatchRecords[j].Record; record != nil {
+				record.setClient(client)
+			}
+		}
+	}
+}
+
+// WithDuplicateRuleHeader sets the duplicate rule header sent with the next Create, Update or Upsert of the SObject.
+// The same SObject pointer is returned to allow chained access. To send the header with every request instead, pass
+// WithHeader("Sforce-Duplicate-Rule-Header", header.String()) to NewClient.
+func (obj *SObject) WithDuplicateRuleHeader(header DuplicateRuleHeader) *SObject {
+	obj.setPrivate(sobjectDuplicateRuleHeaderKey, header.String())
+	return obj
+}
+
+// addDuplicateRuleHeader adds the duplicate rule header set by WithDuplicateRuleHeader to header, and clears it, as it
+// only applies to a single request.
+func (obj *SObject) addDuplicateRuleHeader(header http.Header) http.Header {
+	if value := obj.StringField(sobjectDuplicateRuleHeaderKey); value != "" {
+		header.Set("Sforce-Duplicate-Rule-Header", value)
+	}
+	obj.setPrivate(sobjectDuplicateRuleHeaderKey, "")
+	return header
+}
@@ -0,0 +1,97 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+const duplicatesResponse = `[{
+	"duplicateResult": {
+		"allowSave": false,
+		"duplicateRule": "Standard_Account_Duplicate_Rule",
+		"duplicateRuleEntityType": "Account",
+		"errorMessage": "You're creating a duplicate record. We recommend you use an existing record instead.",
+		"matchResults": [{
+			"entityType": "Account",
+			"errors": [],
+			"matchEngine": "FuzzyMatchEngine",
+			"matchRecords": [{
+				"additionalInformation": [],
+				"fieldDiffs": [{"difference": "SAME", "name": "Name"}],
+				"matchConfidence": 100.0,
+				"record": {"attributes": {"type": "Account", "url": "/services/data/v54.0/sobjects/Account/001000000000001AAA"},
+					"Id": "001000000000001AAA", "Name": "Acme"}
+			}],
+			"rule": "Standard_Account_Match_Rule_v1_0",
+			"size": 1,
+			"success": true
+		}]
+	},
+	"errorCode": "DUPLICATES_DETECTED",
+	"message": "Use one of these records?"
+}]`
+
+func TestSObject_DuplicateRuleHeader(t *testing.T) {
+	var headers []string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Sforce-Duplicate-Rule-Header")
+		headers = append(headers, header)
+		switch {
+		case r.Method == http.MethodPost && header == "":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, duplicatesResponse)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"001000000000002AAA","success":true,"errors":[]}`)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"attributes":{"type":"Account"},"Id":"001000000000001AAA","Name":"Acme"}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	obj := client.SObject("Account").Set("Name", "Acme")
+	if obj.Create() != nil {
+		t.Fatal("expected create to be blocked by the duplicate rule")
+	}
+	if !errors.Is(obj.Err(), ErrDuplicatesDetected) {
+		t.Fatalf("expected ErrDuplicatesDetected, got %v", obj.Err())
+	}
+	var sfErr SalesforceError
+	if !errors.As(obj.Err(), &sfErr) || sfErr.DuplicateResult() == nil {
+		t.Fatalf("expected a duplicate result, got %v", obj.Err())
+	}
+	result := sfErr.DuplicateResult()
+	records := result.Records()
+	if result.DuplicateRule != "Standard_Account_Duplicate_Rule" || len(records) != 1 ||
+		records[0].ID() != "001000000000001AAA" || records[0].StringField("Name") != "Acme" {
+		t.Errorf("unexpected duplicate result %+v", result)
+	}
+	// The matched records are attached to the client.
+	if records[0].Get() == nil || records[0].StringField("Name") != "Acme" {
+		t.Errorf("expected to retrieve the matched record, got %v", records[0].Err())
+	}
+	if diffs := result.MatchResults[0].MatchRecords[0].FieldDiffs; len(diffs) != 1 || diffs[0].Difference != "SAME" {
+		t.Errorf("unexpected field diffs %v", diffs)
+	}
+
+	// The header is only sent with, and cleared by, the next save.
+	header := DuplicateRuleHeader{AllowSave: true, IncludeRecordDetails: true}
+	if obj.WithDuplicateRuleHeader(header).Get("001000000000001AAA") == nil {
+		t.Fatalf("expected get to succeed, got %v", obj.Err())
+	}
+	if obj.Create() == nil || obj.ID() != "001000000000002AAA" {
+		t.Fatalf("expected create to succeed, got %v", obj.Err())
+	}
+	if obj.Set("Name", "Acme Inc").Update() == nil {
+		t.Fatalf("expected update to succeed, got %v", obj.Err())
+	}
+
+	expected := []string{"", "", "", "allowSave=true, includeRecordDetails=true, runAsCurrentUser=false", ""}
+	if fmt.Sprintf("%q", headers) != fmt.Sprintf("%q", expected) {
+		t.Errorf("unexpected headers %q", headers)
+	}
+}
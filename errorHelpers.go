@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"net/http"
 
 	"github.com/pkg/errors"
 )
@@ -14,35 +15,144 @@ var (
 
 	// ErrAuthentication is returned when authentication failed.
 	ErrAuthentication = errors.New("authentication failure")
+
+	// ErrNotFound matches errors returned for records that don't exist or have been deleted.
+	ErrNotFound = errors.New("record not found")
+
+	// ErrRowLocked matches errors returned when a record is locked by another transaction.
+	ErrRowLocked = errors.New("record locked")
+
+	// ErrConflict matches errors returned when the precondition of a conditional request failed, i.e. the record has
+	// been modified since it was retrieved.
+	ErrConflict = errors.New("record modified")
+
+	// ErrChecksumMismatch is returned when downloaded data doesn't match the checksum recorded by Salesforce.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrNullField is returned by the typed field accessors when the field is missing or null.
+	ErrNullField = errors.New("field is null or missing")
+)
+
+// ErrorCode is the error code of an error returned by Salesforce. An ErrorCode matches a SalesforceError with errors.Is
+// if any of the errors returned by Salesforce has the code, e.g. errors.Is(err, ErrInvalidSessionID).
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api.meta/api/sforce_api_calls_concepts_core_data_objects.htm#statuscode
+type ErrorCode string
+
+func (code ErrorCode) Error() string {
+	return string(code)
+}
+
+// Common Salesforce error codes.
+const (
+	// ErrInvalidSessionID is returned when the session has expired or is invalid, and a new login is required.
+	ErrInvalidSessionID ErrorCode = "INVALID_SESSION_ID"
+
+	// ErrEntityIsDeleted is returned when a record has been deleted.
+	ErrEntityIsDeleted ErrorCode = "ENTITY_IS_DELETED"
+
+	// ErrDuplicatesDetected is returned when a duplicate rule blocks saving a record.
+	ErrDuplicatesDetected ErrorCode = "DUPLICATES_DETECTED"
+
+	// ErrUnableToLockRow is returned when a record is locked by another transaction.
+	ErrUnableToLockRow ErrorCode = "UNABLE_TO_LOCK_ROW"
+
+	// ErrRequestLimitExceeded is returned when the org has exceeded its request limits.
+	ErrRequestLimitExceeded ErrorCode = "REQUEST_LIMIT_EXCEEDED"
+
+	// ErrFieldCustomValidation is returned when a validation rule rejects a record.
+	ErrFieldCustomValidation ErrorCode = "FIELD_CUSTOM_VALIDATION_EXCEPTION"
 )
 
-type jsonError []struct {
-	Message   string `json:"message"`
-	ErrorCode string `json:"errorCode"`
+// ErrorDetail is one of the errors returned by Salesforce for a request.
+type ErrorDetail struct {
+	Message   string   `json:"message"`
+	ErrorCode string   `json:"errorCode"`
+	Fields    []string `json:"fields,omitempty"`
+	// DuplicateResult describes the duplicates found for DUPLICATES_DETECTED errors.
+	DuplicateResult *DuplicateResult `json:"duplicateResult,omitempty"`
 }
 
+type jsonError []ErrorDetail
+
 type xmlError struct {
 	Message   string `xml:"Body>Fault>faultstring"`
 	ErrorCode string `xml:"Body>Fault>faultcode"`
 }
 
+// SalesforceError is returned for requests that Salesforce responded to with an error. ErrorCode and ErrorMessage are
+// those of the first error; Errors returns all of them. SalesforceError values are comparable.
 type SalesforceError struct {
 	Message      string
 	HttpCode     int
 	ErrorCode    string
 	ErrorMessage string
+
+	// details holds the JSON encoded errors if there is more to them than ErrorCode and ErrorMessage. It is a string,
+	// so that errors parsed from the same response compare equal.
+	details string
+	// client made the request, and is attached to the records of the DuplicateResult.
+	client *Client
 }
 
 func (err SalesforceError) Error() string {
 	return err.Message
 }
 
+// Is allows a SalesforceError to be matched against the generic errors of this package and against error codes with
+// errors.Is, e.g. errors.Is(err, ErrNotFound) or errors.Is(err, ErrDuplicatesDetected).
+func (err SalesforceError) Is(target error) bool {
+	if code, ok := target.(ErrorCode); ok {
+		return err.HasErrorCode(string(code))
+	}
+
+	switch target {
+	case ErrAuthentication:
+		return err.HttpCode == http.StatusUnauthorized || err.HasErrorCode(string(ErrInvalidSessionID))
+	case ErrNotFound:
+		return err.HttpCode == http.StatusNotFound || err.HasErrorCode("NOT_FOUND") ||
+			err.HasErrorCode(string(ErrEntityIsDeleted))
+	case ErrRowLocked:
+		return err.HasErrorCode(string(ErrUnableToLockRow))
+	case ErrConflict:
+		return err.HttpCode == http.StatusPreconditionFailed
+	default:
+		return false
+	}
+}
+
+// Errors returns all the errors returned by Salesforce, the first of which has ErrorCode and ErrorMessage.
+func (err SalesforceError) Errors() []ErrorDetail {
+	if err.details != "" {
+		var details []ErrorDetail
+		if json.Unmarshal([]byte(err.details), &details) == nil {
+			return details
+		}
+	}
+	if err.ErrorCode == "" && err.ErrorMessage == "" {
+		return nil
+	}
+	return []ErrorDetail{{Message: err.ErrorMessage, ErrorCode: err.ErrorCode}}
+}
+
+// HasErrorCode reports whether any of the errors returned by Salesforce has the error code.
+func (err SalesforceError) HasErrorCode(code string) bool {
+	if err.ErrorCode == code {
+		return true
+	}
+	for _, detail := range err.Errors() {
+		if detail.ErrorCode == code {
+			return true
+		}
+	}
+	return false
+}
+
 //Need to get information out of this package.
 func ParseSalesforceError(statusCode int, responseBody []byte) (err error) {
 	jsonError := jsonError{}
 	err = json.Unmarshal(responseBody, &jsonError)
-	if err == nil {
-		return SalesforceError{
+	if err == nil && len(jsonError) > 0 {
+		sfErr := SalesforceError{
 			Message: fmt.Sprintf(
 				logPrefix+" Error. http code: %v Error Message:  %v Error Code: %v",
 				statusCode, jsonError[0].Message, jsonError[0].ErrorCode,
@@ -51,6 +161,12 @@ func ParseSalesforceError(statusCode int, responseBody []byte) (err error) {
 			ErrorCode:    jsonError[0].ErrorCode,
 			ErrorMessage: jsonError[0].Message,
 		}
+		if len(jsonError) > 1 || len(jsonError[0].Fields) > 0 || jsonError[0].DuplicateResult != nil {
+			if details, err := json.Marshal(jsonError); err == nil {
+				sfErr.details = string(details)
+			}
+		}
+		return sfErr
 	}
 
 	xmlError := xmlError{}
@@ -1,7 +1,11 @@
 package simpleforce
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 var expectedError SalesforceError = SalesforceError{
@@ -54,3 +58,59 @@ func TestUnsuccessfulParse(t *testing.T) {
 		t.Errorf("failed to parse unknown error, got %s", err)
 	}
 }
+
+func TestParseEmptyErrorArray(t *testing.T) {
+	err := ParseSalesforceError(500, []byte("[]"))
+	if err != (SalesforceError{HttpCode: 500, Message: "[]"}) {
+		t.Errorf("failed to parse empty error array, got %#v", err)
+	}
+}
+
+func TestParseMultipleErrors(t *testing.T) {
+	response := `[
+		{"message": "Amount must be positive", "errorCode": "FIELD_CUSTOM_VALIDATION_EXCEPTION", "fields": ["Amount"]},
+		{"message": "unable to obtain exclusive access to this record", "errorCode": "UNABLE_TO_LOCK_ROW", "fields": []}
+	]`
+
+	err := ParseSalesforceError(400, []byte(response))
+	var sfErr SalesforceError
+	if !errors.As(err, &sfErr) || len(sfErr.Errors()) != 2 {
+		t.Fatalf("expected two errors, got %#v", err)
+	}
+	if sfErr.ErrorCode != "FIELD_CUSTOM_VALIDATION_EXCEPTION" || !reflect.DeepEqual(sfErr.Errors()[0].Fields, []string{"Amount"}) {
+		t.Errorf("unexpected first error %#v", sfErr.Errors()[0])
+	}
+
+	// Errors with details compare by value.
+	if err != ParseSalesforceError(400, []byte(response)) {
+		t.Error("expected errors parsed from the same response to be equal")
+	}
+	if err == ParseSalesforceError(400, []byte(strings.Replace(response, "this record", "that record", 1))) {
+		t.Error("expected errors with different details to differ")
+	}
+
+	wrapped := errors.Wrap(err, "update failed")
+	for target, expected := range map[error]bool{
+		ErrFieldCustomValidation: true,
+		ErrUnableToLockRow:       true,
+		ErrRowLocked:             true,
+		ErrDuplicatesDetected:    false,
+		ErrNotFound:              false,
+	} {
+		if errors.Is(wrapped, target) != expected {
+			t.Errorf("expected errors.Is(err, %v) to be %v", target, expected)
+		}
+	}
+}
+
+func TestSalesforceError_Is(t *testing.T) {
+	for body, target := range map[string]error{
+		`[{"message": "Session expired or invalid", "errorCode": "INVALID_SESSION_ID"}]`:        ErrAuthentication,
+		`[{"message": "entity is deleted", "errorCode": "ENTITY_IS_DELETED"}]`:                  ErrNotFound,
+		`[{"message": "TotalRequests Limit exceeded.", "errorCode": "REQUEST_LIMIT_EXCEEDED"}]`: ErrRequestLimitExceeded,
+	} {
+		if err := ParseSalesforceError(400, []byte(body)); !errors.Is(err, target) {
+			t.Errorf("expected %v to match %v", err, target)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ExplainResult holds the execution plans that the query optimizer considered for a query, cheapest first.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/dome_query_explain.htm
+type ExplainResult struct {
+	Plans       []QueryPlan `json:"plans"`
+	SourceQuery string      `json:"sourceQuery"`
+}
+
+// QueryPlan describes an execution plan of a query.
+type QueryPlan struct {
+	// Cardinality is the estimated number of records the leading operation type would return.
+	Cardinality int `json:"cardinality"`
+	// Fields are the indexed fields used by the plan, if any.
+	Fields []string `json:"fields"`
+	// LeadingOperationType is the primary operation type of the plan: "Index", "Other", "Sharing" or "TableScan".
+	LeadingOperationType string `json:"leadingOperationType"`
+	// Notes explain why filters could not be used for optimization.
+	Notes []QueryPlanNote `json:"notes"`
+	// RelativeCost is the cost of the plan compared to the selectivity threshold of the query optimizer.
+	RelativeCost float64 `json:"relativeCost"`
+	// SObjectCardinality is the approximate number of records of the queried object.
+	SObjectCardinality int `json:"sobjectCardinality"`
+	// SObjectType is the queried object.
+	SObjectType string `json:"sobjectType"`
+}
+
+// QueryPlanNote explains why a filter could not be used for optimization.
+type QueryPlanNote struct {
+	Description   string   `json:"description"`
+	Fields        []string `json:"fields"`
+	TableEnumOrID string   `json:"tableEnumOrId"`
+}
+
+// IsSelective reports whether the plan is below the selectivity threshold of the query optimizer, i.e. its relative
+// cost is less than 1.
+func (plan QueryPlan) IsSelective() bool {
+	return plan.RelativeCost < 1
+}
+
+// Explain returns the execution plans of the SOQL query q without running it, to check whether the query is selective
+// before running it against a large object.
+func (client *Client) Explain(q string) (*ExplainResult, error) {
+	if !client.isLoggedIn() {
+		return nil, ErrAuthentication
+	}
+
+	resource := "query"
+	if client.useToolingAPI {
+		resource = "tooling/query"
+	}
+	u := fmt.Sprintf("%s/services/data/v%s/%s?explain=%s", client.instanceURL(), client.apiVersion, resource, url.QueryEscape(q))
+	data, err := client.httpRequest(operation{name: "Explain"}, http.MethodGet, u, nil)
+	if err != nil {
+		client.logln("HTTP GET request failed:", u)
+		return nil, err
+	}
+
+	var result ExplainResult
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
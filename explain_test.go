@@ -0,0 +1,51 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Explain(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/data/v54.0/query" || r.URL.Query().Get("explain") != "SELECT Id FROM Account WHERE CreatedDate = TODAY" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `{
+			"plans": [{
+				"cardinality": 2843,
+				"fields": ["CreatedDate"],
+				"leadingOperationType": "Index",
+				"notes": [],
+				"relativeCost": 0.036,
+				"sobjectCardinality": 49000,
+				"sobjectType": "Account"
+			}, {
+				"cardinality": 2843,
+				"fields": [],
+				"leadingOperationType": "TableScan",
+				"notes": [{"description": "Not considering filter for optimization because unindexed", "fields": ["IsDeleted"], "tableEnumOrId": "Account"}],
+				"relativeCost": 1.65,
+				"sobjectCardinality": 49000,
+				"sobjectType": "Account"
+			}],
+			"sourceQuery": "SELECT Id FROM Account WHERE CreatedDate = TODAY"
+		}`)
+	})
+
+	result, err := client.Explain("SELECT Id FROM Account WHERE CreatedDate = TODAY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Plans) != 2 || result.SourceQuery == "" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	index, scan := result.Plans[0], result.Plans[1]
+	if index.LeadingOperationType != "Index" || index.Cardinality != 2843 || index.SObjectCardinality != 49000 ||
+		index.Fields[0] != "CreatedDate" || !index.IsSelective() {
+		t.Errorf("unexpected index plan %+v", index)
+	}
+	if scan.IsSelective() || len(scan.Notes) != 1 || scan.Notes[0].TableEnumOrID != "Account" || scan.Notes[0].Fields[0] != "IsDeleted" {
+		t.Errorf("unexpected table scan plan %+v", scan)
+	}
+}
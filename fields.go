@@ -0,0 +1,333 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DateLayout is the layout of Salesforce date fields, e.g. "2006-01-02".
+	DateLayout = "2006-01-02"
+	// DateTimeLayout is the layout of Salesforce datetime fields, e.g. "2006-01-02T15:04:05.000+0000".
+	DateTimeLayout = "2006-01-02T15:04:05.000-0700"
+	// TimeLayout is the layout of Salesforce time fields, e.g. "15:04:05.000Z".
+	TimeLayout = "15:04:05.000Z07:00"
+)
+
+// IntField accesses a field in the SObject as an integer. Number fields are returned by Salesforce as JSON numbers,
+// so an error is returned if the value has a fractional part.
+func (obj *SObject) IntField(key string) (int64, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		// math.MaxInt64 is not representable as a float64 and rounds up to 2^63, so compare against 2^63 directly.
+		if v != math.Trunc(v) || v >= 1<<63 || v < -(1<<63) {
+			return 0, errors.Errorf("field %s: %v is not an integer", key, v)
+		}
+		return int64(v), nil
+	case json.Number:
+		// Salesforce serializes whole numbers of number and currency fields with a fraction, e.g. 12.0.
+		r, ok := new(big.Rat).SetString(string(v))
+		if !ok || !r.IsInt() || !r.Num().IsInt64() {
+			return 0, errors.Errorf("field %s: %v is not an integer", key, v)
+		}
+		return r.Num().Int64(), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return 0, fieldTypeError(key, value, "int")
+	}
+}
+
+// FloatField accesses a field in the SObject as a float64.
+func (obj *SObject) FloatField(key string) (float64, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fieldTypeError(key, value, "float")
+	}
+}
+
+// DecimalField accesses a currency, percent or number field in the SObject as an exact decimal. Values decoded as
+// float64 are converted using their shortest decimal representation, i.e. 0.1 is returned as exactly 1/10.
+func (obj *SObject) DecimalField(key string) (*big.Rat, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var s string
+	switch v := value.(type) {
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		s = v.String()
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case string:
+		s = v
+	default:
+		return nil, fieldTypeError(key, value, "decimal")
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errors.Errorf("field %s: %q is not a decimal", key, s)
+	}
+	return r, nil
+}
+
+// NumberField accesses a number field in the SObject as a json.Number. Numbers are only kept in their original
+// representation if the client has been configured with SetUseNumber; float64 values are formatted in their shortest
+// representation otherwise.
+func (obj *SObject) NumberField(key string) (json.Number, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		return v, nil
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case int:
+		return json.Number(strconv.Itoa(v)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	default:
+		return "", fieldTypeError(key, value, "number")
+	}
+}
+
+// BoolField accesses a checkbox field in the SObject as a bool.
+func (obj *SObject) BoolField(key string) (bool, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return false, err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		return false, fieldTypeError(key, value, "bool")
+	}
+}
+
+// DateField accesses a date field in the SObject. The returned time is midnight UTC of that date.
+func (obj *SObject) DateField(key string) (time.Time, error) {
+	return obj.timeField(key, DateLayout)
+}
+
+// DateTimeField accesses a datetime field in the SObject, e.g. "2006-01-02T15:04:05.000+0000". RFC 3339 values are
+// accepted as well since they are commonly set locally before the SObject is sent to Salesforce.
+func (obj *SObject) DateTimeField(key string) (time.Time, error) {
+	return obj.timeField(key, DateTimeLayout, time.RFC3339Nano)
+}
+
+// TimeField accesses a time field in the SObject, e.g. "15:04:05.000Z". The date part of the returned time is zero.
+func (obj *SObject) TimeField(key string) (time.Time, error) {
+	return obj.timeField(key, TimeLayout)
+}
+
+// timeField parses a field with the first layout that matches.
+func (obj *SObject) timeField(key string, layouts ...string) (time.Time, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range layouts {
+			t, err := time.Parse(layout, v)
+			if err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, errors.Errorf("field %s: %q does not match layout %q", key, v, layouts[0])
+	default:
+		return time.Time{}, fieldTypeError(key, value, "time")
+	}
+}
+
+// nonNullField returns the raw value of a field, or ErrNullField if the field is missing or null.
+func (obj *SObject) nonNullField(key string) (interface{}, error) {
+	value := obj.InterfaceField(key)
+	if value == nil {
+		return nil, errors.Wrapf(ErrNullField, "field %s", key)
+	}
+	return value, nil
+}
+
+func fieldTypeError(key string, value interface{}, kind string) error {
+	return errors.Errorf("field %s: cannot read %T as %s", key, value, kind)
+}
+
+// Address describes a compound address field, e.g. BillingAddress of Account. Empty components are left out when the
+// address is written to Salesforce.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api.meta/api/compound_fields_address.htm
+type Address struct {
+	Street          string   `json:"street,omitempty"`
+	City            string   `json:"city,omitempty"`
+	State           string   `json:"state,omitempty"`
+	StateCode       string   `json:"stateCode,omitempty"`
+	PostalCode      string   `json:"postalCode,omitempty"`
+	Country         string   `json:"country,omitempty"`
+	CountryCode     string   `json:"countryCode,omitempty"`
+	Latitude        *float64 `json:"latitude,omitempty"`
+	Longitude       *float64 `json:"longitude,omitempty"`
+	GeocodeAccuracy string   `json:"geocodeAccuracy,omitempty"`
+}
+
+// Components expands the address into the component fields of the compound field key, e.g. "BillingAddress" is
+// expanded to "BillingStreet", "BillingCity", etc. and "Home__c" to "Home__Street__s", "Home__City__s", etc.
+func (addr *Address) Components(key string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for component, value := range map[string]string{
+		"Street":          addr.Street,
+		"City":            addr.City,
+		"State":           addr.State,
+		"StateCode":       addr.StateCode,
+		"PostalCode":      addr.PostalCode,
+		"Country":         addr.Country,
+		"CountryCode":     addr.CountryCode,
+		"GeocodeAccuracy": addr.GeocodeAccuracy,
+	} {
+		if value != "" {
+			fields[componentFieldName(key, "Address", component)] = value
+		}
+	}
+	if addr.Latitude != nil {
+		fields[componentFieldName(key, "Address", "Latitude")] = *addr.Latitude
+	}
+	if addr.Longitude != nil {
+		fields[componentFieldName(key, "Address", "Longitude")] = *addr.Longitude
+	}
+	return fields
+}
+
+// Geolocation describes a compound geolocation field.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api.meta/api/compound_fields_geolocation.htm
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Components expands the geolocation into the component fields of the compound field key, e.g. "Location__c" is
+// expanded to "Location__Latitude__s" and "Location__Longitude__s".
+func (geo *Geolocation) Components(key string) map[string]interface{} {
+	return map[string]interface{}{
+		componentFieldName(key, "Location", "Latitude"):  geo.Latitude,
+		componentFieldName(key, "Location", "Longitude"): geo.Longitude,
+	}
+}
+
+// AddressField accesses a compound address field in the SObject, e.g. "BillingAddress".
+func (obj *SObject) AddressField(key string) (*Address, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case Address:
+		return &v, nil
+	case *Address:
+		return v, nil
+	case map[string]interface{}:
+		addr := &Address{}
+		return addr, decodeCompoundField(key, v, addr)
+	default:
+		return nil, fieldTypeError(key, value, "address")
+	}
+}
+
+// GeolocationField accesses a compound geolocation field in the SObject, e.g. "Location__c".
+func (obj *SObject) GeolocationField(key string) (*Geolocation, error) {
+	value, err := obj.nonNullField(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case Geolocation:
+		return &v, nil
+	case *Geolocation:
+		return v, nil
+	case map[string]interface{}:
+		geo := &Geolocation{}
+		return geo, decodeCompoundField(key, v, geo)
+	default:
+		return nil, fieldTypeError(key, value, "geolocation")
+	}
+}
+
+// decodeCompoundField converts the decoded JSON object of a compound field into its concrete type.
+func decodeCompoundField(key string, mapper map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(mapper)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	return errors.Wrapf(err, "field %s", key)
+}
+
+// componentFieldName returns the name of a component field of a compound field. Standard compound fields share a
+// prefix with their components (BillingAddress, BillingCity), while custom ones use the "__s" suffix.
+func componentFieldName(key, compoundSuffix, component string) string {
+	if strings.HasSuffix(key, "__c") {
+		return strings.TrimSuffix(key, "__c") + "__" + component + "__s"
+	}
+	return strings.TrimSuffix(key, compoundSuffix) + component
+}
+
+// compoundComponents expands Address and Geolocation values into their component fields.
+func compoundComponents(key string, value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case Address:
+		return v.Components(key), true
+	case *Address:
+		return v.Components(key), true
+	case Geolocation:
+		return v.Components(key), true
+	case *Geolocation:
+		return v.Components(key), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,169 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestSObject_IntField(t *testing.T) {
+	obj := &SObject{
+		"NumberOfEmployees": float64(250),
+		"Rating":            1.5,
+		"Text":              "42",
+	}
+
+	if v, err := obj.IntField("NumberOfEmployees"); err != nil || v != 250 {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	if v, err := obj.IntField("Text"); err != nil || v != 42 {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	if _, err := obj.IntField("Rating"); err == nil {
+		t.Error("expected error for fractional value")
+	}
+	for _, v := range []float64{9.223372036854775807e18, -9.3e18} {
+		if _, err := (&SObject{"Big": v}).IntField("Big"); err == nil {
+			t.Errorf("expected error for out of range value %v", v)
+		}
+	}
+	if v, err := (&SObject{"Min": float64(-1 << 63)}).IntField("Min"); err != nil || v != -1<<63 {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	numbers := &SObject{"Whole": json.Number("12.0"), "Fraction": json.Number("12.5"), "Huge": json.Number("1e19")}
+	if v, err := numbers.IntField("Whole"); err != nil || v != 12 {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	if _, err := numbers.IntField("Fraction"); err == nil {
+		t.Error("expected error for fractional json.Number")
+	}
+	if _, err := numbers.IntField("Huge"); err == nil {
+		t.Error("expected error for out of range json.Number")
+	}
+	if _, err := obj.IntField("Missing"); !errors.Is(err, ErrNullField) {
+		t.Errorf("expected ErrNullField, got %v", err)
+	}
+}
+
+func TestSObject_FloatField(t *testing.T) {
+	obj := &SObject{"Amount": 12.5, "Name": "Acme"}
+
+	if v, err := obj.FloatField("Amount"); err != nil || v != 12.5 {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	if _, err := obj.FloatField("Name"); err == nil {
+		t.Error("expected error for non-numeric value")
+	}
+}
+
+func TestSObject_DecimalField(t *testing.T) {
+	obj := &SObject{"Amount": 0.1, "Precise": "12345678901234567.89"}
+
+	v, err := obj.DecimalField("Amount")
+	if err != nil || v.Cmp(big.NewRat(1, 10)) != 0 {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	v, err = obj.DecimalField("Precise")
+	if err != nil || v.FloatString(2) != "12345678901234567.89" {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+}
+
+func TestSObject_BoolField(t *testing.T) {
+	obj := &SObject{"IsClosed": true, "Name": "Acme"}
+
+	if v, err := obj.BoolField("IsClosed"); err != nil || !v {
+		t.Errorf("unexpected value %v, %v", v, err)
+	}
+	if _, err := obj.BoolField("Name"); err == nil {
+		t.Error("expected error for non-boolean value")
+	}
+}
+
+func TestSObject_TimeFields(t *testing.T) {
+	obj := &SObject{
+		"CloseDate":   "2022-04-29",
+		"CreatedDate": "2022-04-29T10:15:30.000+0000",
+		"StartTime":   "13:37:00.000Z",
+		"Bad":         "yesterday",
+	}
+
+	d, err := obj.DateField("CloseDate")
+	if err != nil || !d.Equal(time.Date(2022, 4, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date %v, %v", d, err)
+	}
+	dt, err := obj.DateTimeField("CreatedDate")
+	if err != nil || !dt.Equal(time.Date(2022, 4, 29, 10, 15, 30, 0, time.UTC)) {
+		t.Errorf("unexpected datetime %v, %v", dt, err)
+	}
+	tm, err := obj.TimeField("StartTime")
+	if err != nil || tm.Hour() != 13 || tm.Minute() != 37 {
+		t.Errorf("unexpected time %v, %v", tm, err)
+	}
+	if _, err := obj.DateTimeField("Bad"); err == nil {
+		t.Error("expected error for invalid datetime")
+	}
+}
+
+func TestSObject_AddressField(t *testing.T) {
+	obj := &SObject{
+		"BillingAddress": map[string]interface{}{
+			"street":     "1 Market St",
+			"city":       "San Francisco",
+			"postalCode": "94105",
+			"latitude":   37.79,
+		},
+	}
+
+	addr, err := obj.AddressField("BillingAddress")
+	if err != nil || addr.City != "San Francisco" || addr.Latitude == nil || *addr.Latitude != 37.79 {
+		t.Errorf("unexpected address %+v, %v", addr, err)
+	}
+	if addr != nil && addr.Longitude != nil {
+		t.Error("expected longitude to be nil")
+	}
+	if _, err := obj.AddressField("ShippingAddress"); !errors.Is(err, ErrNullField) {
+		t.Errorf("expected ErrNullField, got %v", err)
+	}
+}
+
+func TestSObject_GeolocationField(t *testing.T) {
+	obj := &SObject{
+		"Location__c": map[string]interface{}{"latitude": 37.79, "longitude": -122.4},
+	}
+
+	geo, err := obj.GeolocationField("Location__c")
+	if err != nil || geo.Latitude != 37.79 || geo.Longitude != -122.4 {
+		t.Errorf("unexpected geolocation %+v, %v", geo, err)
+	}
+}
+
+func TestSObject_makeCopyCompoundFields(t *testing.T) {
+	lat := 37.79
+	obj := &SObject{
+		"BillingAddress": Address{Street: "1 Market St", City: "San Francisco", Latitude: &lat},
+		"Location__c":    &Geolocation{Latitude: 37.79, Longitude: -122.4},
+		"Home__c":        Address{City: "Oakland"},
+	}
+
+	copied := obj.makeCopy()
+	expected := map[string]interface{}{
+		"BillingStreet":          "1 Market St",
+		"BillingCity":            "San Francisco",
+		"BillingLatitude":        37.79,
+		"Location__Latitude__s":  37.79,
+		"Location__Longitude__s": -122.4,
+		"Home__City__s":          "Oakland",
+	}
+	if len(copied) != len(expected) {
+		t.Errorf("unexpected fields %v", copied)
+	}
+	for k, v := range expected {
+		if copied[k] != v {
+			t.Errorf("field %s: expected %v, got %v", k, v, copied[k])
+		}
+	}
+}
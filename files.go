@@ -0,0 +1,157 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ContentVersionUpload holds the IDs of the records created by UploadContentVersion.
+type ContentVersionUpload struct {
+	ContentVersionID       string
+	ContentDocumentID      string
+	ContentDocumentLinkIDs []string
+}
+
+// UploadContentVersion creates a ContentVersion (a Salesforce file) with the data read from r. The data is streamed to
+// Salesforce as a multipart request, so it is never held in memory as a whole. If linkedEntityIDs are provided, the
+// file is shared with each of the records through a ContentDocumentLink.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/dome_sobject_insert_update_blob.htm
+func (client *Client) UploadContentVersion(title, pathOnClient string, r io.Reader, linkedEntityIDs ...string) (*ContentVersionUpload, error) {
+	entity := map[string]interface{}{
+		"Title":        title,
+		"PathOnClient": pathOnClient,
+	}
+	id, err := client.upload("ContentVersion", "entity_content", "VersionData", entity, pathOnClient, r)
+	if err != nil {
+		return nil, err
+	}
+	result := &ContentVersionUpload{ContentVersionID: id}
+	if len(linkedEntityIDs) == 0 {
+		return result, nil
+	}
+
+	version := client.SObject("ContentVersion")
+	if version.GetFields(id, "ContentDocumentId") == nil {
+		return result, errors.Wrap(version.Err(), "failed to retrieve ContentDocumentId")
+	}
+	result.ContentDocumentID = version.StringField("ContentDocumentId")
+
+	for _, linkedEntityID := range linkedEntityIDs {
+		link := client.SObject("ContentDocumentLink").
+			Set("ContentDocumentId", result.ContentDocumentID).
+			Set("LinkedEntityId", linkedEntityID).
+			Set("ShareType", "V")
+		if link.Create() == nil {
+			return result, errors.Wrapf(link.Err(), "failed to link file to %s", linkedEntityID)
+		}
+		result.ContentDocumentLinkIDs = append(result.ContentDocumentLinkIDs, link.ID())
+	}
+	return result, nil
+}
+
+// UploadAttachment creates an Attachment of the record parentID with the data read from r, and returns its ID.
+func (client *Client) UploadAttachment(parentID, name string, r io.Reader) (string, error) {
+	entity := map[string]interface{}{
+		"ParentId": parentID,
+		"Name":     name,
+	}
+	return client.upload("Attachment", "entity_attachment", "Body", entity, name, r)
+}
+
+// UploadDocument creates a Document in the folder folderID with the data read from r, and returns its ID.
+func (client *Client) UploadDocument(folderID, name string, r io.Reader) (string, error) {
+	entity := map[string]interface{}{
+		"FolderId": folderID,
+		"Name":     name,
+	}
+	return client.upload("Document", "entity_document", "Body", entity, name, r)
+}
+
+// upload creates an SObject with a blob field through a multipart request. The first part holds the JSON encoded
+// fields of the SObject and the second part the binary data, which is copied from r while the request is sent.
+func (client *Client) upload(sobjectType, entityPart, blobField string, entity map[string]interface{}, filename string, r io.Reader) (string, error) {
+	if !client.isLoggedIn() {
+		return "", ErrAuthentication
+	}
+
+	entityData, err := json.Marshal(entity)
+	if err != nil {
+		return "", err
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(writeUploadParts(mw, entityPart, entityData, blobField, filename, r))
+	}()
+	// Unblock the writer if the request ends before the body has been consumed, and wait for it, so that r is no longer
+	// read once upload returns.
+	defer func() {
+		pr.Close()
+		<-done
+	}()
+
+	header := http.Header{}
+	header.Set("Content-Type", mw.FormDataContentType())
+	u := client.makeURL("sobjects/" + sobjectType + "/")
+	respData, _, err := client.httpRequestHeader(operation{name: "Upload", sobjectType: sobjectType}, http.MethodPost, u, streamingBody{pr}, header)
+	if err != nil {
+		client.logln("HTTP POST request failed:", u)
+		return "", err
+	}
+
+	var respVal struct {
+		ID      string `json:"id"`
+		Success bool   `json:"success"`
+	}
+	err = json.Unmarshal(respData, &respVal)
+	if err != nil {
+		return "", err
+	}
+	if !respVal.Success || respVal.ID == "" {
+		return "", errors.New("request was unsuccessful")
+	}
+	return respVal.ID, nil
+}
+
+// writeUploadParts writes the entity and blob parts of an upload request.
+func writeUploadParts(mw *multipart.Writer, entityPart string, entityData []byte, blobField, filename string, r io.Reader) error {
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, entityPart))
+	partHeader.Set("Content-Type", "application/json")
+	part, err := mw.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	if _, err = part.Write(entityData); err != nil {
+		return err
+	}
+
+	partHeader = textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition",
+		fmt.Sprintf(`form-data; name="%s"; filename="%s"`, blobField, escapeQuotes(filename)))
+	partHeader.Set("Content-Type", "application/octet-stream")
+	part, err = mw.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(part, r); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,132 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_UploadContentVersion(t *testing.T) {
+	var entity map[string]interface{}
+	var blob string
+	var links []string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/sobjects/ContentVersion/"):
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			for part, err := mr.NextPart(); err == nil; part, err = mr.NextPart() {
+				data, _ := ioutil.ReadAll(part)
+				switch part.FormName() {
+				case "entity_content":
+					json.Unmarshal(data, &entity)
+				case "VersionData":
+					if part.FileName() != "report.csv" {
+						t.Errorf("unexpected file name %s", part.FileName())
+					}
+					blob = string(data)
+				}
+			}
+			fmt.Fprint(w, `{"id":"068000000000001","success":true,"errors":[]}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/sobjects/ContentVersion/068000000000001"):
+			fmt.Fprint(w, `{"attributes":{"type":"ContentVersion"},"Id":"068000000000001","ContentDocumentId":"069000000000001"}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/sobjects/ContentDocumentLink/"):
+			var link map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&link)
+			links = append(links, link["LinkedEntityId"].(string))
+			fmt.Fprintf(w, `{"id":"06A00000000000%d","success":true,"errors":[]}`, len(links))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	result, err := client.UploadContentVersion("Report", "report.csv", strings.NewReader("a,b\n1,2\n"),
+		"001000000000001", "001000000000002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ContentVersionID != "068000000000001" || result.ContentDocumentID != "069000000000001" ||
+		len(result.ContentDocumentLinkIDs) != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if entity["Title"] != "Report" || blob != "a,b\n1,2\n" {
+		t.Errorf("unexpected upload %v, %q", entity, blob)
+	}
+	if len(links) != 2 || links[1] != "001000000000002" {
+		t.Errorf("unexpected links %v", links)
+	}
+}
+
+func TestClient_UploadAttachmentFailure(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `[{"message":"Required fields are missing: [ParentId]","errorCode":"REQUIRED_FIELD_MISSING"}]`)
+	})
+
+	id, err := client.UploadAttachment("", "notes.txt", strings.NewReader(strings.Repeat("x", 1<<20)))
+	if err == nil || id != "" {
+		t.Errorf("expected error, got %s", id)
+	}
+}
+
+func TestClient_UploadAttachmentStopsReading(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+	// Fail the request after reading part of the data, while more of it is being read.
+	client.Use(func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			buf := make([]byte, 512)
+			for n := 0; n < 512; {
+				m, err := req.HTTPRequest.Body.Read(buf)
+				if err != nil {
+					return nil, err
+				}
+				n += m
+			}
+			return nil, errors.New("connection reset by peer")
+		}
+	})
+
+	r := &slowReader{}
+	if _, err := client.UploadAttachment("001000000000001AAA", "notes.txt", r); err == nil {
+		t.Fatal("expected error")
+	}
+	reading := atomic.LoadInt32(&r.reading)
+	atomic.StoreInt32(&r.returned, 1)
+	time.Sleep(50 * time.Millisecond)
+	if reading != 0 || atomic.LoadInt32(&r.readAfterReturn) != 0 {
+		t.Error("expected the reader not to be read after the upload returned")
+	}
+}
+
+// slowReader is an endless reader returning small chunks, which records whether it is read after returned is set.
+type slowReader struct {
+	reading         int32
+	returned        int32
+	readAfterReturn int32
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	atomic.AddInt32(&r.reading, 1)
+	defer atomic.AddInt32(&r.reading, -1)
+	if atomic.LoadInt32(&r.returned) != 0 {
+		atomic.StoreInt32(&r.readAfterReturn, 1)
+	}
+	time.Sleep(10 * time.Millisecond)
+	n := copy(p, strings.Repeat("x", 100))
+	return n, nil
+}
@@ -2,6 +2,10 @@ package simpleforce
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -13,6 +17,10 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 const (
@@ -21,23 +29,45 @@ const (
 	DefaultURL        = "https://login.salesforce.com"
 
 	logPrefix = "[simpleforce]"
+
+	// maxDownloadResumes is how many times an interrupted download is resumed before giving up.
+	maxDownloadResumes = 3
 )
 
-// Client is the main instance to access salesforce.
+// Client is the main instance to access salesforce. A Client may be used by multiple goroutines at once, but its
+// setters should be called before it is shared.
 type Client struct {
-	sessionID string
-	user      struct {
-		id       string
-		name     string
-		fullName string
-		email    string
-	}
+	session       *session
 	clientID      string
 	apiVersion    string
 	baseURL       string
-	instanceURL   string
 	useToolingAPI bool
+	useNumber     bool
 	httpClient    *http.Client
+	usage         *apiUsageTracker
+	retryPolicy   *RetryPolicy
+	limiter       *rateLimiter
+	logger        *log.Logger
+	header        http.Header
+	compression   bool
+	middleware    []Middleware
+	timeout       time.Duration
+	proxyURL      *url.URL
+	ctx           context.Context
+}
+
+// session holds the login state of a client. It is shared by reference with the Tooling view of the client, so that
+// both use the same session.
+type session struct {
+	mu          sync.RWMutex
+	id          string
+	instanceURL string
+	user        struct {
+		id       string
+		name     string
+		fullName string
+		email    string
+	}
 }
 
 // QueryResult holds the response data from an SOQL query.
@@ -46,26 +76,45 @@ type QueryResult struct {
 	Done           bool      `json:"done"`
 	NextRecordsURL string    `json:"nextRecordsUrl"`
 	Records        []SObject `json:"records"`
+
+	client *Client // fetches the next page, see NextPage.
 }
 
 // Expose sid to save in admin settings
 func (client *Client) GetSid() (sid string) {
-        return client.sessionID
+	return client.sessionID()
 }
 
-//Expose Loc to save in admin settings
+// Expose Loc to save in admin settings
 func (client *Client) GetLoc() (loc string) {
-	return client.instanceURL
+	return client.instanceURL()
 }
 
 // Set SID and Loc as a means to log in without LoginPassword
 func (client *Client) SetSidLoc(sid string, loc string) {
-        client.sessionID = sid
-        client.instanceURL = loc
+	client.session.mu.Lock()
+	defer client.session.mu.Unlock()
+	client.session.id = sid
+	client.session.instanceURL = loc
+}
+
+// sessionID returns the ID of the current session.
+func (client *Client) sessionID() string {
+	client.session.mu.RLock()
+	defer client.session.mu.RUnlock()
+	return client.session.id
 }
 
-// Query runs an SOQL query. q could either be the SOQL string or the nextRecordsURL.
-func (client *Client) Query(q string) (*QueryResult, error) {
+// instanceURL returns the URL of the instance the current session belongs to.
+func (client *Client) instanceURL() string {
+	client.session.mu.RLock()
+	defer client.session.mu.RUnlock()
+	return client.session.instanceURL
+}
+
+// Query runs an SOQL query. q could either be the SOQL string or the nextRecordsURL. opts set headers of the call, e.g.
+// QueryBatchSize.
+func (client *Client) Query(q string, opts ...CallOption) (*QueryResult, error) {
 	if !client.isLoggedIn() {
 		return nil, ErrAuthentication
 	}
@@ -73,33 +122,31 @@ func (client *Client) Query(q string) (*QueryResult, error) {
 	var u string
 	if strings.HasPrefix(q, "/services/data") {
 		// q is nextRecordsURL.
-		u = fmt.Sprintf("%s%s", client.instanceURL, q)
+		u = fmt.Sprintf("%s%s", client.instanceURL(), q)
 	} else {
 		// q is SOQL.
 		formatString := "%s/services/data/v%s/query?q=%s"
-		baseURL := client.instanceURL
+		baseURL := client.instanceURL()
 		if client.useToolingAPI {
 			formatString = strings.Replace(formatString, "query", "tooling/query", -1)
 		}
 		u = fmt.Sprintf(formatString, baseURL, client.apiVersion, url.QueryEscape(q))
 	}
 
-	data, err := client.httpRequest("GET", u, nil)
+	data, _, err := client.httpRequestHeader(operation{name: "Query"}, http.MethodGet, u, nil, callHeader(opts))
 	if err != nil {
-		log.Println(logPrefix, "HTTP GET request failed:", u)
+		client.logln("HTTP GET request failed:", u)
 		return nil, err
 	}
 
 	var result QueryResult
-	err = json.Unmarshal(data, &result)
+	err = client.unmarshal(data, &result)
 	if err != nil {
 		return nil, err
 	}
 
 	// Reference to client is needed if the object will be further used to do online queries.
-	for idx := range result.Records {
-		result.Records[idx].setClient(client)
-	}
+	result.setClient(client)
 
 	return &result, nil
 }
@@ -110,11 +157,11 @@ func (client *Client) ApexREST(method, path string, requestBody io.Reader) ([]by
 		return nil, ErrAuthentication
 	}
 
-	u := fmt.Sprintf("%s/%s", client.instanceURL, path)
+	u := fmt.Sprintf("%s/%s", client.instanceURL(), path)
 
-	data, err := client.httpRequest(method, u, requestBody)
+	data, err := client.httpRequest(operation{name: "ApexREST"}, method, u, requestBody)
 	if err != nil {
-		log.Println(logPrefix, fmt.Sprintf("HTTP %s request failed:", method), u)
+		client.logln(fmt.Sprintf("HTTP %s request failed:", method), u)
 		return nil, err
 	}
 
@@ -133,7 +180,7 @@ func (client *Client) SObject(typeName ...string) *SObject {
 
 // isLoggedIn returns if the login to salesforce is successful.
 func (client *Client) isLoggedIn() bool {
-	return client.sessionID != ""
+	return client.sessionID() != ""
 }
 
 // LoginPassword signs into salesforce using password. token is optional if trusted IP is configured.
@@ -167,34 +214,27 @@ func (client *Client) LoginPassword(username, password, token string) error {
 	url := fmt.Sprintf("%s/services/Soap/u/%s", client.baseURL, client.apiVersion)
 	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(soapBody))
 	if err != nil {
-		log.Println(logPrefix, "error occurred creating request,", err)
+		client.logln("error occurred creating request,", err)
 		return err
 	}
 	req.Header.Add("Content-Type", "text/xml")
 	req.Header.Add("charset", "UTF-8")
 	req.Header.Add("SOAPAction", "login")
+	if userAgent := client.header.Get("User-Agent"); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
 
-	resp, err := client.httpClient.Do(req)
+	resp, err := client.send(operation{name: "Login"}, req)
 	if err != nil {
-		log.Println(logPrefix, "error occurred submitting request,", err)
+		client.logln("error occurred submitting request,", err)
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Println(logPrefix, "request failed,", resp.StatusCode)
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		newStr := buf.String()
-		log.Println(logPrefix, "Failed resp.body: ", newStr)
-		theError := ParseSalesforceError(resp.StatusCode, buf.Bytes())
-		return theError
-	}
-
 	respData, err := ioutil.ReadAll(resp.Body)
 
 	if err != nil {
-		log.Println(logPrefix, "error occurred reading response data,", err)
+		client.logln("error occurred reading response data,", err)
 	}
 
 	var loginResponse struct {
@@ -209,66 +249,142 @@ func (client *Client) LoginPassword(username, password, token string) error {
 
 	err = xml.Unmarshal(respData, &loginResponse)
 	if err != nil {
-		log.Println(logPrefix, "error occurred parsing login response,", err)
+		client.logln("error occurred parsing login response,", err)
 		return err
 	}
 
 	// Now we should all be good and the sessionID can be used to talk to salesforce further.
-	client.sessionID = loginResponse.SessionID
-	client.instanceURL = parseHost(loginResponse.ServerURL)
-	client.user.id = loginResponse.UserID
-	client.user.name = loginResponse.UserName
-	client.user.email = loginResponse.UserEmail
-	client.user.fullName = loginResponse.UserFullName
-
-	log.Println(logPrefix, "User", client.user.name, "authenticated.")
+	client.session.mu.Lock()
+	client.session.id = loginResponse.SessionID
+	client.session.instanceURL = parseHost(loginResponse.ServerURL)
+	client.session.user.id = loginResponse.UserID
+	client.session.user.name = loginResponse.UserName
+	client.session.user.email = loginResponse.UserEmail
+	client.session.user.fullName = loginResponse.UserFullName
+	client.session.mu.Unlock()
+
+	client.logln("User", loginResponse.UserName, "authenticated.")
 	return nil
 }
 
+// WithContext returns a lightweight view of the client, like Tooling, whose requests are made with ctx. Cancelling ctx
+// aborts the requests in flight and the waits between retries, which then return the error of ctx.
+func (client *Client) WithContext(ctx context.Context) *Client {
+	view := *client
+	view.ctx = ctx
+	return &view
+}
+
+// requestContext returns the context of the requests made by the client.
+func (client *Client) requestContext() context.Context {
+	if client.ctx == nil {
+		return context.Background()
+	}
+	return client.ctx
+}
+
 // httpRequest executes an HTTP request to the salesforce server and returns the response data in byte buffer.
-func (client *Client) httpRequest(method, url string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, url, body)
+func (client *Client) httpRequest(op operation, method, url string, body io.Reader) ([]byte, error) {
+	data, _, err := client.httpRequestHeader(op, method, url, body, nil)
+	return data, err
+}
+
+// httpRequestHeader works like httpRequest, but also sends the provided request headers and returns the response
+// headers. Failed requests are retried according to the retry policy of the client; the body is buffered so that it
+// can be resent, unless it is a streamingBody.
+func (client *Client) httpRequestHeader(op operation, method, url string, body io.Reader, header http.Header) ([]byte, http.Header, error) {
+	policy := client.retryPolicy
+	var bodyData []byte
+	if _, ok := body.(streamingBody); ok {
+		policy = nil
+	} else if body != nil && (policy != nil || client.compression) {
+		var err error
+		bodyData, err = ioutil.ReadAll(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		if client.compression {
+			bodyData, err = gzipData(bodyData)
+			if err != nil {
+				return nil, nil, err
+			}
+			header = header.Clone()
+			if header == nil {
+				header = http.Header{}
+			}
+			header.Set("Content-Encoding", "gzip")
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		if bodyData != nil {
+			body = bytes.NewReader(bodyData)
+		}
+		data, respHeader, err := client.doHTTPRequest(op, method, url, body, header)
+		retry, backoff := policy.shouldRetry(method, attempt, respHeader, err)
+		if !retry {
+			return data, respHeader, err
+		}
+		client.logln("request failed, retrying in", backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-client.requestContext().Done():
+			timer.Stop()
+			return nil, respHeader, client.requestContext().Err()
+		}
+	}
+}
+
+// doHTTPRequest executes a single attempt of an HTTP request.
+func (client *Client) doHTTPRequest(op operation, method, url string, body io.Reader, header http.Header) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(client.requestContext(), method, url, body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", client.sessionID))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", client.sessionID()))
 	req.Header.Add("Content-Type", "application/json")
+	client.setHeaders(req)
+	for key, values := range header {
+		req.Header[key] = values
+	}
 
-	resp, err := client.httpClient.Do(req)
+	resp, err := client.send(op, req)
 	if err != nil {
-		return nil, err
+		if resp != nil {
+			return nil, resp.Header, err
+		}
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		log.Println(logPrefix, "request failed,", resp.StatusCode)
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		newStr := buf.String()
-		theError := ParseSalesforceError(resp.StatusCode, buf.Bytes())
-		log.Println(logPrefix, "Failed resp.body: ", newStr)
-		return nil, theError
-	}
-
-	return ioutil.ReadAll(resp.Body)
+	data, err := ioutil.ReadAll(resp.Body)
+	return data, resp.Header, err
 }
 
 // makeURL generates a REST API URL based on baseURL, APIVersion of the client.
 func (client *Client) makeURL(req string) string {
-	client.apiVersion = strings.Replace(client.apiVersion, "v", "", -1)
-	retURL := fmt.Sprintf("%s/services/data/v%s/%s", client.instanceURL, client.apiVersion, req)
+	retURL := fmt.Sprintf("%s/services/data/v%s/%s", client.instanceURL(), client.apiVersion, req)
 	return retURL
 }
 
-// NewClient creates a new instance of the client.
-func NewClient(url, clientID, apiVersion string) *Client {
+// NewClient creates a new instance of the client. The client can be further configured with options, e.g.
+// NewClient(url, DefaultClientID, DefaultAPIVersion, WithTimeout(time.Minute), WithRetryPolicy(DefaultRetryPolicy())).
+func NewClient(url, clientID, apiVersion string, opts ...ClientOption) *Client {
 	client := &Client{
-		apiVersion: apiVersion,
+		session:    &session{},
+		apiVersion: strings.Replace(apiVersion, "v", "", -1),
 		baseURL:    url,
 		clientID:   clientID,
 		httpClient: &http.Client{},
+		usage:      &apiUsageTracker{},
+		limiter:    &rateLimiter{},
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
+	client.configureHTTPClient()
 
 	// Remove trailing "/" from base url to prevent "//" when paths are appended
 	if strings.HasSuffix(client.baseURL, "/") {
@@ -277,10 +393,38 @@ func NewClient(url, clientID, apiVersion string) *Client {
 	return client
 }
 
+// gzipData compresses data with gzip.
+func gzipData(data []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (client *Client) SetHttpClient(c *http.Client) {
 	client.httpClient = c
 }
 
+// SetUseNumber makes the client decode numbers in records and metadata as json.Number instead of float64, so that
+// currency and number fields can be read without losing precision, e.g. with DecimalField or NumberField.
+func (client *Client) SetUseNumber(useNumber bool) {
+	client.useNumber = useNumber
+}
+
+// unmarshal decodes JSON response data into v, honoring the number decoding setting of the client.
+func (client *Client) unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if client.useNumber {
+		decoder.UseNumber()
+	}
+	return decoder.Decode(v)
+}
+
 // DownloadFile downloads a file based on the REST API path given. Saves to filePath.
 func (client *Client) DownloadFile(contentVersionID string, filepath string) error {
 	apiPath := fmt.Sprintf("/services/data/v%s/sobjects/ContentVersion/%s/VersionData", client.apiVersion, contentVersionID)
@@ -292,38 +436,124 @@ func (client *Client) DownloadAttachment(attachmentId string, filepath string) e
 	return client.download(apiPath, filepath)
 }
 
-func (client *Client) download(apiPath string, filepath string) error {
-	// Get the data
-	httpClient := client.httpClient
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s%s", strings.TrimRight(client.instanceURL, "/"), apiPath), nil)
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+client.sessionID)
+// DownloadFileTo downloads the data of a ContentVersion to w. Interrupted downloads are resumed where they stopped,
+// and the data is verified against the Checksum field of the ContentVersion; ErrChecksumMismatch is returned if it
+// doesn't match.
+func (client *Client) DownloadFileTo(ctx context.Context, contentVersionID string, w io.Writer) error {
+	version := client.SObject("ContentVersion")
+	if version.GetFields(contentVersionID, "Checksum") == nil {
+		return version.Err()
+	}
 
-	resp, err := httpClient.Do(req)
+	hash := md5.New()
+	apiPath := fmt.Sprintf("/services/data/v%s/sobjects/ContentVersion/%s/VersionData", client.apiVersion, contentVersionID)
+	err := client.downloadTo(ctx, apiPath, io.MultiWriter(w, hash))
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		return fmt.Errorf("ERROR: statuscode: %d, body: %s", resp.StatusCode, buf.String())
+	checksum := version.StringField("Checksum")
+	if checksum != "" && !strings.EqualFold(checksum, hex.EncodeToString(hash.Sum(nil))) {
+		return ErrChecksumMismatch
 	}
+	return nil
+}
 
+// DownloadAttachmentTo downloads the body of an Attachment to w. Interrupted downloads are resumed where they stopped.
+func (client *Client) DownloadAttachmentTo(ctx context.Context, attachmentID string, w io.Writer) error {
+	apiPath := fmt.Sprintf("/services/data/v%s/sobjects/Attachment/%s/Body", client.apiVersion, attachmentID)
+	return client.downloadTo(ctx, apiPath, w)
+}
+
+// DownloadDocumentTo downloads the body of a Document to w. Interrupted downloads are resumed where they stopped.
+func (client *Client) DownloadDocumentTo(ctx context.Context, documentID string, w io.Writer) error {
+	apiPath := fmt.Sprintf("/services/data/v%s/sobjects/Document/%s/Body", client.apiVersion, documentID)
+	return client.downloadTo(ctx, apiPath, w)
+}
+
+func (client *Client) download(apiPath string, filepath string) error {
 	// Create the file
 	out, err := os.Create(filepath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
 
-	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
+	// Write the body to file, and don't leave a partial file behind if the download failed.
+	err = client.downloadTo(context.Background(), apiPath, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filepath)
+	}
+	return err
+}
+
+// downloadTo downloads the blob at apiPath to w. If reading the response fails, the download is resumed with a Range
+// request for the remaining bytes, up to maxDownloadResumes times.
+func (client *Client) downloadTo(ctx context.Context, apiPath string, w io.Writer) error {
+	cw := &countingWriter{w: w}
+	for resumes := 0; ; resumes++ {
+		err := client.downloadRange(ctx, apiPath, cw)
+		if err == nil {
+			return nil
+		}
+
+		// Only failures reading the response can be resumed; HTTP errors and write errors are final.
+		var sfErr SalesforceError
+		if cw.err != nil || errors.As(err, &sfErr) || ctx.Err() != nil || resumes >= maxDownloadResumes {
+			return err
+		}
+		client.logln("download interrupted after", cw.n, "bytes, resuming,", err)
+	}
+}
+
+// downloadRange downloads the blob at apiPath to cw, starting at the number of bytes already written to cw.
+func (client *Client) downloadRange(ctx context.Context, apiPath string, cw *countingWriter) error {
+	u := fmt.Sprintf("%s%s", strings.TrimRight(client.instanceURL(), "/"), apiPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", "Bearer "+client.sessionID())
+	client.setHeaders(req)
+	if cw.n > 0 {
+		req.Header.Add("Range", fmt.Sprintf("bytes=%d-", cw.n))
+	}
+
+	resp, err := client.send(operation{name: "Download"}, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The server may ignore the Range header and send the whole blob again.
+	if cw.n > 0 && resp.StatusCode != http.StatusPartialContent {
+		if _, err = io.CopyN(ioutil.Discard, resp.Body, cw.n); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.Copy(cw, resp.Body)
 	return err
 }
 
+// countingWriter counts the bytes written to w and keeps the error returned by w, if any.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
 func parseHost(input string) string {
 	parsed, err := url.Parse(input)
 	if err == nil {
@@ -337,13 +567,15 @@ func (client *Client) DescribeGlobal() (*SObjectMeta, error) {
 	apiPath := fmt.Sprintf("/services/data/v%s/sobjects", client.apiVersion)
 	baseURL := strings.TrimRight(client.baseURL, "/")
 	url := fmt.Sprintf("%s%s", baseURL, apiPath) // Get the objects
-	httpClient := client.httpClient
 	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+client.sessionID)
-	// resp, err := http.Get(url)
-	resp, err := httpClient.Do(req)
+	req.Header.Add("Authorization", "Bearer "+client.sessionID())
+	client.setHeaders(req)
+	resp, err := client.send(operation{name: "DescribeGlobal"}, req)
 	if err != nil {
 		return nil, err
 	}
@@ -352,12 +584,12 @@ func (client *Client) DescribeGlobal() (*SObjectMeta, error) {
 	var meta SObjectMeta
 
 	respData, err := ioutil.ReadAll(resp.Body)
-	log.Println(logPrefix, fmt.Sprintf("status code %d", resp.StatusCode))
+	client.logln(fmt.Sprintf("status code %d", resp.StatusCode))
 	if err != nil {
-		log.Println(logPrefix, "error while reading all body")
+		client.logln("error while reading all body")
 	}
 
-	err = json.Unmarshal(respData, &meta)
+	err = client.unmarshal(respData, &meta)
 	if err != nil {
 		return nil, err
 	}
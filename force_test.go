@@ -1,7 +1,14 @@
 package simpleforce
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -56,7 +63,7 @@ func TestClient_LoginPassword(t *testing.T) {
 	if err != nil {
 		t.Fail()
 	} else {
-		log.Println(logPrefix, "sessionID:", client.sessionID)
+		log.Println(logPrefix, "sessionID:", client.sessionID())
 	}
 
 	err = client.LoginPassword("__INVALID_USER__", "__INVALID_PASS__", "__INVALID_TOKEN__")
@@ -78,7 +85,7 @@ func TestClient_LoginPasswordNoToken(t *testing.T) {
 	if err != nil {
 		t.FailNow()
 	} else {
-		log.Println(logPrefix, "sessionID:", client.sessionID)
+		log.Println(logPrefix, "sessionID:", client.sessionID())
 	}
 }
 
@@ -176,6 +183,87 @@ func TestClient_QueryLike(t *testing.T) {
 	}
 }
 
+// requireTestServer starts a local server with handler and returns a client logged into it.
+func requireTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, DefaultClientID, DefaultAPIVersion)
+	client.SetSidLoc("__SESSION_ID__", server.URL)
+	return client
+}
+
+func TestClient_QueryUseNumber(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"totalSize":1,"done":true,"records":[
+			{"attributes":{"type":"Opportunity"},"Amount":12345678901234567.89,
+			 "Account":{"attributes":{"type":"Account","url":"/services/data/v54.0/sobjects/Account/001"},"AnnualRevenue":0.1}}
+		]}`)
+	})
+	client.SetUseNumber(true)
+
+	result, err := client.Query("SELECT Amount, Account.AnnualRevenue FROM Opportunity")
+	if err != nil || len(result.Records) != 1 {
+		t.Fatal(err)
+	}
+	amount, err := result.Records[0].NumberField("Amount")
+	if err != nil || amount.String() != "12345678901234567.89" {
+		t.Errorf("unexpected amount %v, %v", amount, err)
+	}
+	revenue, err := result.Records[0].SObjectField("Account", "Account").DecimalField("AnnualRevenue")
+	if err != nil || revenue.FloatString(1) != "0.1" {
+		t.Errorf("unexpected revenue %v, %v", revenue, err)
+	}
+}
+
+func TestClient_DownloadFileTo(t *testing.T) {
+	content := strings.Repeat("simpleforce ", 1000)
+	checksum := fmt.Sprintf("%x", md5.Sum([]byte(content)))
+	var ranges []string
+	dropConnection := true
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/VersionData") {
+			fmt.Fprintf(w, `{"attributes":{"type":"ContentVersion"},"Id":"068000000000001","Checksum":"%s"}`, checksum)
+			return
+		}
+
+		ranges = append(ranges, r.Header.Get("Range"))
+		switch {
+		case r.Header.Get("Range") != "":
+			w.WriteHeader(http.StatusPartialContent)
+			fmt.Fprint(w, content[len(content)/2:])
+		case dropConnection:
+			// Send half of the content, then drop the connection.
+			conn, buf, _ := w.(http.Hijacker).Hijack()
+			fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(content), content[:len(content)/2])
+			buf.Flush()
+			conn.Close()
+		default:
+			fmt.Fprint(w, content)
+		}
+	})
+
+	var buf bytes.Buffer
+	err := client.DownloadFileTo(context.Background(), "068000000000001", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != content {
+		t.Errorf("unexpected content of length %d", buf.Len())
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != fmt.Sprintf("bytes=%d-", len(content)/2) {
+		t.Errorf("unexpected ranges %v", ranges)
+	}
+
+	// The checksum is verified against the downloaded data.
+	checksum = "00000000000000000000000000000000"
+	dropConnection = false
+	err = client.DownloadFileTo(context.Background(), "068000000000001", ioutil.Discard)
+	if err != ErrChecksumMismatch {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
 func TestMain(m *testing.M) {
 	m.Run()
 }
@@ -0,0 +1,126 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Limit describes the maximum and remaining allocation of an org limit.
+type Limit struct {
+	Max       int `json:"Max"`
+	Remaining int `json:"Remaining"`
+}
+
+// Used returns how much of the limit has been used.
+func (limit Limit) Used() int {
+	return limit.Max - limit.Remaining
+}
+
+// Limits maps the names of org limits, e.g. "DailyApiRequests", to their allocation.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_limits.htm
+type Limits map[string]Limit
+
+// Limits returns the org limits and their remaining allocation.
+func (client *Client) Limits() (Limits, error) {
+	if !client.isLoggedIn() {
+		return nil, ErrAuthentication
+	}
+
+	u := client.makeURL("limits/")
+	data, err := client.httpRequest(operation{name: "Limits"}, http.MethodGet, u, nil)
+	if err != nil {
+		client.logln("HTTP GET request failed:", u)
+		return nil, err
+	}
+
+	var limits Limits
+	err = json.Unmarshal(data, &limits)
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// APIUsage describes the API requests made by the org within the last 24 hours, as reported by the Sforce-Limit-Info
+// header of the last response.
+type APIUsage struct {
+	Used int
+	Max  int
+}
+
+// APIUsage returns the API usage reported by the last response, or a zero APIUsage if none has been reported yet.
+func (client *Client) APIUsage() APIUsage {
+	client.usage.mu.Lock()
+	defer client.usage.mu.Unlock()
+	return client.usage.current
+}
+
+// SetAPIUsageThreshold registers callback to be invoked when the reported API usage reaches threshold, a fraction of
+// the maximum between 0 and 1. The callback is invoked once each time the threshold is crossed, synchronously from the
+// goroutine that made the request, so it should return quickly. A nil callback removes the threshold.
+func (client *Client) SetAPIUsageThreshold(threshold float64, callback func(APIUsage)) {
+	client.usage.mu.Lock()
+	defer client.usage.mu.Unlock()
+	client.usage.threshold = threshold
+	client.usage.callback = callback
+	client.usage.exceeded = false
+}
+
+// apiUsageTracker keeps the API usage reported by Salesforce. It is shared by reference so that the usage is tracked
+// per session rather than per Client value.
+type apiUsageTracker struct {
+	mu        sync.Mutex
+	current   APIUsage
+	threshold float64
+	callback  func(APIUsage)
+	exceeded  bool
+}
+
+// update records the API usage reported in the Sforce-Limit-Info header, e.g. "api-usage=25/5000", and invokes the
+// threshold callback if the threshold has just been crossed.
+func (tracker *apiUsageTracker) update(header http.Header) {
+	usage, ok := ParseAPIUsage(header)
+	if !ok {
+		return
+	}
+
+	tracker.mu.Lock()
+	tracker.current = usage
+	callback := tracker.callback
+	reached := callback != nil && float64(usage.Used) >= tracker.threshold*float64(usage.Max)
+	crossed := reached && !tracker.exceeded
+	tracker.exceeded = reached
+	tracker.mu.Unlock()
+
+	if crossed {
+		callback(usage)
+	}
+}
+
+// ParseAPIUsage parses the api-usage entry of the Sforce-Limit-Info header of a response, e.g. "api-usage=25/5000".
+// It returns false if the header doesn't report the API usage.
+func ParseAPIUsage(header http.Header) (APIUsage, bool) {
+	for _, entry := range strings.Split(header.Get("Sforce-Limit-Info"), ",") {
+		entry = strings.TrimSpace(entry)
+		if !strings.HasPrefix(entry, "api-usage=") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(entry, "api-usage="), "/", 2)
+		if len(parts) != 2 {
+			return APIUsage{}, false
+		}
+		used, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return APIUsage{}, false
+		}
+		max, err := strconv.Atoi(parts[1])
+		if err != nil || max <= 0 {
+			return APIUsage{}, false
+		}
+		return APIUsage{Used: used, Max: max}, true
+	}
+	return APIUsage{}, false
+}
@@ -0,0 +1,61 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_Limits(t *testing.T) {
+	used := 0
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		used += 2000
+		w.Header().Set("Sforce-Limit-Info", fmt.Sprintf("api-usage=%d/5000", used))
+		if !strings.HasSuffix(r.URL.Path, "/limits/") {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprintf(w, `{"DailyApiRequests":{"Max":5000,"Remaining":%d,"Ant Migration Tool":{"Max":0,"Remaining":0}},
+			"DataStorageMB":{"Max":1024,"Remaining":1000}}`, 5000-used)
+	})
+
+	var notified []APIUsage
+	client.SetAPIUsageThreshold(0.5, func(usage APIUsage) {
+		notified = append(notified, usage)
+	})
+
+	limits, err := client.Limits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits["DailyApiRequests"].Max != 5000 || limits["DailyApiRequests"].Used() != 2000 ||
+		limits["DataStorageMB"].Remaining != 1000 {
+		t.Errorf("unexpected limits %v", limits)
+	}
+	if usage := client.APIUsage(); usage.Used != 2000 || usage.Max != 5000 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	if len(notified) != 0 {
+		t.Errorf("unexpected notification %v", notified)
+	}
+
+	// The callback is invoked once when the threshold is crossed.
+	client.Limits()
+	client.Limits()
+	if len(notified) != 1 || notified[0].Used != 4000 {
+		t.Errorf("unexpected notifications %v", notified)
+	}
+}
+
+func TestParseAPIUsage(t *testing.T) {
+	header := http.Header{}
+	header.Set("Sforce-Limit-Info", "api-usage=18/5000, per-app-api-usage=17/250(appName=sample-app)")
+	usage, ok := ParseAPIUsage(header)
+	if !ok || usage.Used != 18 || usage.Max != 5000 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	header.Set("Sforce-Limit-Info", "per-app-api-usage=17/250(appName=sample-app)")
+	if _, ok := ParseAPIUsage(header); ok {
+		t.Error("expected no api usage")
+	}
+}
@@ -0,0 +1,104 @@
+package simpleforce
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Request describes a request to Salesforce as seen by the middleware of a client.
+type Request struct {
+	// Operation names the client operation making the request, e.g. "Query", "Create", "Download" or "Login".
+	Operation string
+	// SObjectType is the type of the SObject the request operates on, if any.
+	SObjectType string
+	// HTTPRequest is the HTTP request to be sent. Middleware may modify it, e.g. to add headers.
+	HTTPRequest *http.Request
+}
+
+// Handler sends a Request. Like an http.RoundTripper, it returns the HTTP response or an error, except that error
+// responses are returned with both: the response, with its body already read, and a SalesforceError parsed from it.
+type Handler func(req *Request) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or modify requests and responses, e.g. to add tracing headers or to record
+// latency metrics. A Middleware must call next to send the request.
+type Middleware func(next Handler) Handler
+
+// Use adds middleware to the client. Every request made by the client, including logins and downloads, passes through
+// the middleware in the order they were added, i.e. the first middleware sees the request first. Retried requests pass
+// through the middleware once per attempt. Use should be called before the client is shared.
+func (client *Client) Use(middleware ...Middleware) {
+	client.middleware = append(client.middleware[:len(client.middleware):len(client.middleware)], middleware...)
+}
+
+// operation identifies the client operation making a request, see Request.
+type operation struct {
+	name        string
+	sobjectType string
+}
+
+// send passes httpReq through the middleware of the client and sends it.
+func (client *Client) send(op operation, httpReq *http.Request) (*http.Response, error) {
+	handler := client.roundTrip
+	for i := len(client.middleware) - 1; i >= 0; i-- {
+		handler = client.middleware[i](handler)
+	}
+	return handler(&Request{
+		Operation:   op.name,
+		SObjectType: op.sobjectType,
+		HTTPRequest: httpReq,
+	})
+}
+
+// roundTrip is the innermost Handler. It waits for the rate limit, sends the request, tracks the reported API usage and
+// parses error responses.
+func (client *Client) roundTrip(req *Request) (*http.Response, error) {
+	release, err := client.limiter.acquire(req.HTTPRequest.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.httpClient.Do(req.HTTPRequest)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	client.usage.update(resp.Header)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer release()
+		client.logln("request failed,", resp.StatusCode)
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		resp.Body.Close()
+		client.logln("Failed resp.body: ", buf.String())
+		resp.Body = ioutil.NopCloser(bytes.NewReader(buf.Bytes()))
+		err := ParseSalesforceError(resp.StatusCode, buf.Bytes())
+		if sfErr, ok := err.(SalesforceError); ok {
+			sfErr.client = client
+			err = sfErr
+		}
+		return resp, err
+	}
+
+	// Keep the request in flight until its body has been read.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody calls release once the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	closed  bool
+}
+
+func (body *releasingBody) Close() error {
+	err := body.ReadCloser.Close()
+	if !body.closed {
+		body.closed = true
+		body.release()
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestClient_Use(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace-Id") != "trace" {
+			t.Errorf("missing trace header")
+		}
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"totalSize":0,"done":true,"records":[]}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `[{"message":"The requested resource does not exist","errorCode":"NOT_FOUND"}]`)
+	})
+
+	var calls []string
+	var lastErr error
+	client.Use(func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			calls = append(calls, "outer "+req.Operation+" "+req.SObjectType)
+			req.HTTPRequest.Header.Set("X-Trace-Id", "trace")
+			resp, err := next(req)
+			lastErr = err
+			return resp, err
+		}
+	}, func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			if req.HTTPRequest.Header.Get("X-Trace-Id") != "trace" {
+				t.Error("expected middleware to run in order")
+			}
+			calls = append(calls, "inner "+req.Operation)
+			return next(req)
+		}
+	})
+
+	if _, err := client.Query("SELECT Id FROM Case"); err != nil {
+		t.Fatal(err)
+	}
+	if lastErr != nil {
+		t.Errorf("unexpected error %v", lastErr)
+	}
+	if client.SObject("Case").Set("Id", "500").Delete() == nil {
+		t.Error("expected delete to fail")
+	}
+	if !errors.Is(lastErr, ErrNotFound) {
+		t.Errorf("expected middleware to see the Salesforce error, got %v", lastErr)
+	}
+
+	expected := []string{"outer Query ", "inner Query", "outer Delete Case", "inner Delete"}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
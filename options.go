@@ -0,0 +1,168 @@
+package simpleforce
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// SandboxURL is the login URL of sandbox orgs.
+	SandboxURL = "https://test.salesforce.com"
+)
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient makes the client send its requests with httpClient. The http.Client is copied if it needs to be
+// modified by other options, e.g. WithTimeout or WithProxy.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the time limit of each request, including reading the response body. Keep in mind that large
+// downloads may need more time than regular requests.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) {
+		client.timeout = timeout
+	}
+}
+
+// WithProxy makes the client send its requests through the proxy at proxyURL. It requires the transport of the HTTP
+// client to be an *http.Transport, which is the default.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(client *Client) {
+		client.proxyURL = proxyURL
+	}
+}
+
+// WithLogger makes the client log to logger instead of the standard logger. Use a logger writing to ioutil.Discard
+// to silence the client.
+func WithLogger(logger *log.Logger) ClientOption {
+	return func(client *Client) {
+		client.logger = logger
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the client, see SetRetryPolicy.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(client *Client) {
+		client.retryPolicy = policy
+	}
+}
+
+// WithRateLimit sets the rate limit of the client, see SetRateLimit.
+func WithRateLimit(limit RateLimit) ClientOption {
+	return func(client *Client) {
+		client.limiter.configure(limit)
+	}
+}
+
+// WithUseNumber makes the client decode numbers as json.Number, see SetUseNumber.
+func WithUseNumber() ClientOption {
+	return func(client *Client) {
+		client.useNumber = true
+	}
+}
+
+// WithUserAgent sets the User-Agent header of all requests.
+func WithUserAgent(userAgent string) ClientOption {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithHeader adds a header to all requests sent to the REST API, e.g. "Sforce-Call-Options" or "Sforce-Auto-Assign".
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers.htm
+func WithHeader(key, value string) ClientOption {
+	return func(client *Client) {
+		if client.header == nil {
+			client.header = http.Header{}
+		}
+		client.header.Add(key, value)
+	}
+}
+
+// WithMiddleware adds middleware to the client, see Use.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(client *Client) {
+		client.Use(middleware...)
+	}
+}
+
+// WithCompression makes the client gzip request bodies. Responses are always requested and decoded with gzip by the
+// HTTP transport. Streamed uploads are not compressed.
+func WithCompression() ClientOption {
+	return func(client *Client) {
+		client.compression = true
+	}
+}
+
+// WithSandbox makes the client log in through the sandbox login URL.
+func WithSandbox() ClientOption {
+	return func(client *Client) {
+		client.baseURL = SandboxURL
+	}
+}
+
+// WithMyDomain makes the client log in through the My Domain login URL of the org, e.g. "acme" for
+// "https://acme.my.salesforce.com". A fully qualified domain name or URL is used as is.
+func WithMyDomain(domain string) ClientOption {
+	return func(client *Client) {
+		switch {
+		case strings.Contains(domain, "://"):
+			client.baseURL = strings.TrimSuffix(domain, "/")
+		case strings.Contains(domain, "."):
+			client.baseURL = "https://" + domain
+		default:
+			client.baseURL = "https://" + domain + ".my.salesforce.com"
+		}
+	}
+}
+
+// configureHTTPClient applies the timeout and proxy options to a copy of the HTTP client of the client.
+func (client *Client) configureHTTPClient() {
+	if client.timeout == 0 && client.proxyURL == nil {
+		return
+	}
+
+	httpClient := *client.httpClient
+	if client.timeout != 0 {
+		httpClient.Timeout = client.timeout
+	}
+	if client.proxyURL != nil {
+		var transport *http.Transport
+		switch t := httpClient.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		}
+		if transport != nil {
+			transport.Proxy = http.ProxyURL(client.proxyURL)
+			httpClient.Transport = transport
+		} else {
+			client.logln("proxy ignored, unsupported transport", httpClient.Transport)
+		}
+	}
+	client.httpClient = &httpClient
+}
+
+// setHeaders adds the headers configured with WithHeader to req.
+func (client *Client) setHeaders(req *http.Request) {
+	for key, values := range client.header {
+		req.Header[key] = values
+	}
+}
+
+// logln logs through the logger of the client, with the prefix of this package. It is safe to call on a nil client.
+func (client *Client) logln(v ...interface{}) {
+	v = append([]interface{}{logPrefix}, v...)
+	if client == nil || client.logger == nil {
+		log.Println(v...)
+		return
+	}
+	client.logger.Println(v...)
+}
@@ -0,0 +1,92 @@
+package simpleforce
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient_LoginURLOptions(t *testing.T) {
+	if client := NewClient(DefaultURL, DefaultClientID, DefaultAPIVersion, WithSandbox()); client.baseURL != SandboxURL {
+		t.Errorf("unexpected sandbox URL %s", client.baseURL)
+	}
+
+	for domain, expected := range map[string]string{
+		"acme":                                "https://acme.my.salesforce.com",
+		"acme--dev.sandbox.my.salesforce.com": "https://acme--dev.sandbox.my.salesforce.com",
+		"https://login.acme.com/":             "https://login.acme.com",
+	} {
+		client := NewClient(DefaultURL, DefaultClientID, DefaultAPIVersion, WithMyDomain(domain))
+		if client.baseURL != expected {
+			t.Errorf("domain %s: expected %s, got %s", domain, expected, client.baseURL)
+		}
+	}
+}
+
+func TestNewClient_HTTPClientOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	proxyURL, _ := url.Parse("http://proxy.example.com:3128")
+	client := NewClient(DefaultURL, DefaultClientID, DefaultAPIVersion,
+		WithHTTPClient(httpClient), WithTimeout(time.Minute), WithProxy(proxyURL))
+
+	if client.httpClient == httpClient || httpClient.Timeout != 0 {
+		t.Error("expected the HTTP client to be copied")
+	}
+	if client.httpClient.Timeout != time.Minute {
+		t.Errorf("unexpected timeout %v", client.httpClient.Timeout)
+	}
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("unexpected transport %T", client.httpClient.Transport)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://login.salesforce.com", nil)
+	if u, err := transport.Proxy(req); err != nil || u.String() != proxyURL.String() {
+		t.Errorf("unexpected proxy %v, %v", u, err)
+	}
+}
+
+func TestNewClient_RequestOptions(t *testing.T) {
+	var header http.Header
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, _ := ioutil.ReadAll(zr)
+		body = string(data)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `[{"message":"bad","errorCode":"BAD"}]`)
+	}))
+	defer server.Close()
+
+	logs := new(bytes.Buffer)
+	client := NewClient(server.URL, DefaultClientID, DefaultAPIVersion,
+		WithUserAgent("acme-sync/1.0"),
+		WithHeader("Sforce-Call-Options", "client=acme-sync"),
+		WithCompression(),
+		WithLogger(log.New(logs, "", 0)))
+	client.SetSidLoc("__SESSION_ID__", server.URL)
+
+	client.SObject("Case").Set("Subject", "Compressed").Create()
+	if header.Get("User-Agent") != "acme-sync/1.0" || header.Get("Sforce-Call-Options") != "client=acme-sync" ||
+		header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("unexpected headers %v", header)
+	}
+	if body != `{"Subject":"Compressed"}` {
+		t.Errorf("unexpected body %s", body)
+	}
+	if !strings.Contains(logs.String(), logPrefix+" request failed, 400") {
+		t.Errorf("expected failure to be logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,25 @@
+module github.com/simpleforce/simpleforce/otelsimpleforce
+
+go 1.23
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/simpleforce/simpleforce v0.0.0
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/metric v1.35.0
+	go.opentelemetry.io/otel/sdk v1.35.0
+	go.opentelemetry.io/otel/sdk/metric v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+// The core module is developed in the same repository. Require a tagged release instead once one includes
+// simpleforce.Middleware.
+replace github.com/simpleforce/simpleforce => ../
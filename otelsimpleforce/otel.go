@@ -0,0 +1,194 @@
+// Package otelsimpleforce instruments simpleforce clients with OpenTelemetry.
+//
+// It is a separate module so that the simpleforce package itself does not depend on OpenTelemetry. Install it with
+//
+//	client.Use(otelsimpleforce.Middleware())
+//
+// or pass it to simpleforce.NewClient with simpleforce.WithMiddleware. Every request made by the client, including
+// queries, SObject operations, Apex REST calls, anonymous Apex and downloads, then produces a client span and is
+// recorded by the request counter and duration histogram. Retried requests produce one span per attempt.
+package otelsimpleforce
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/simpleforce/simpleforce"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ScopeName is the instrumentation scope of the tracer and meter.
+	ScopeName = "github.com/simpleforce/simpleforce/otelsimpleforce"
+
+	// Attribute keys specific to Salesforce.
+	OperationKey   = attribute.Key("salesforce.operation")
+	SObjectTypeKey = attribute.Key("salesforce.sobject_type")
+	SOQLKey        = attribute.Key("salesforce.soql")
+	ErrorCodeKey   = attribute.Key("salesforce.error_code")
+	APIUsedKey     = attribute.Key("salesforce.api_usage.used")
+	APIMaxKey      = attribute.Key("salesforce.api_usage.max")
+
+	// Semantic convention attribute keys.
+	httpMethodKey     = attribute.Key("http.request.method")
+	httpStatusCodeKey = attribute.Key("http.response.status_code")
+	serverAddressKey  = attribute.Key("server.address")
+)
+
+// Option configures the middleware created by Middleware.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the tracer provider. It defaults to the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider. It defaults to the global meter provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(cfg *config) {
+		cfg.meterProvider = provider
+	}
+}
+
+// Middleware returns a simpleforce.Middleware that traces requests and records the following metrics:
+//
+//   - simpleforce.requests: a counter of the requests made, by operation, SObject type, status code and error code.
+//   - simpleforce.request.duration: a histogram of the request latency in seconds, with the same attributes.
+//
+// Spans carry the operation, the SObject type, the SOQL of queries with its literals redacted, the HTTP status, the
+// Salesforce error code and the API usage reported by Salesforce.
+func Middleware(opts ...Option) simpleforce.Middleware {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tracer := cfg.tracerProvider.Tracer(ScopeName)
+	meter := cfg.meterProvider.Meter(ScopeName)
+	requests, err := meter.Int64Counter("simpleforce.requests",
+		metric.WithDescription("Number of requests made to Salesforce."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram("simpleforce.request.duration",
+		metric.WithDescription("Duration of requests made to Salesforce."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next simpleforce.Handler) simpleforce.Handler {
+		return func(req *simpleforce.Request) (*http.Response, error) {
+			httpReq := req.HTTPRequest
+			attrs := []attribute.KeyValue{OperationKey.String(req.Operation)}
+			if req.SObjectType != "" {
+				attrs = append(attrs, SObjectTypeKey.String(req.SObjectType))
+			}
+
+			spanAttrs := append([]attribute.KeyValue{
+				httpMethodKey.String(httpReq.Method),
+				serverAddressKey.String(httpReq.URL.Hostname()),
+			}, attrs...)
+			if req.Operation == "Query" {
+				if soql := httpReq.URL.Query().Get("q"); soql != "" {
+					spanAttrs = append(spanAttrs, SOQLKey.String(RedactSOQL(soql)))
+				}
+			}
+
+			ctx, span := tracer.Start(httpReq.Context(), "Salesforce "+req.Operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(spanAttrs...))
+			req.HTTPRequest = httpReq.WithContext(ctx)
+
+			start := time.Now()
+			resp, err := next(req)
+
+			if resp != nil {
+				attrs = append(attrs, httpStatusCodeKey.Int(resp.StatusCode))
+				span.SetAttributes(httpStatusCodeKey.Int(resp.StatusCode))
+				if usage, ok := simpleforce.ParseAPIUsage(resp.Header); ok {
+					span.SetAttributes(APIUsedKey.Int(usage.Used), APIMaxKey.Int(usage.Max))
+				}
+			}
+			if err != nil {
+				var sfErr simpleforce.SalesforceError
+				if errors.As(err, &sfErr) && sfErr.ErrorCode != "" {
+					attrs = append(attrs, ErrorCodeKey.String(sfErr.ErrorCode))
+					span.SetAttributes(ErrorCodeKey.String(sfErr.ErrorCode))
+				}
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			set := metric.WithAttributes(attrs...)
+			if requests != nil {
+				requests.Add(ctx, 1, set)
+			}
+			finish := func() {
+				if duration != nil {
+					duration.Record(ctx, time.Since(start).Seconds(), set)
+				}
+				span.End()
+			}
+			if err != nil || resp == nil || resp.Body == nil {
+				finish()
+				return resp, err
+			}
+
+			// The request lasts until its body has been read, which matters for downloads.
+			resp.Body = &finishingBody{ReadCloser: resp.Body, finish: finish}
+			return resp, nil
+		}
+	}
+}
+
+// finishingBody calls finish once the body is closed.
+type finishingBody struct {
+	io.ReadCloser
+	finish func()
+	closed bool
+}
+
+func (body *finishingBody) Close() error {
+	err := body.ReadCloser.Close()
+	if !body.closed {
+		body.closed = true
+		body.finish()
+	}
+	return err
+}
+
+var (
+	soqlStringLiteral  = regexp.MustCompile(`'(?:[^'\\]|\\.)*'`)
+	soqlDateLiteral    = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?`)
+	soqlNumericLiteral = regexp.MustCompile(`(?:^|[^\w.])[-+]?\d+(?:\.\d+)?\b`)
+	soqlNumericDigits  = regexp.MustCompile(`[-+]?\d+(?:\.\d+)?`)
+)
+
+// RedactSOQL replaces the string, date and numeric literals of a SOQL query with "?", so that it can be recorded
+// without leaking the values it filters on.
+func RedactSOQL(soql string) string {
+	soql = soqlStringLiteral.ReplaceAllString(soql, "?")
+	soql = soqlDateLiteral.ReplaceAllString(soql, "?")
+	return soqlNumericLiteral.ReplaceAllStringFunc(soql, func(match string) string {
+		return soqlNumericDigits.ReplaceAllString(match, "?")
+	})
+}
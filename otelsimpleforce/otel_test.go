@@ -0,0 +1,134 @@
+package otelsimpleforce
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/simpleforce/simpleforce"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRedactSOQL(t *testing.T) {
+	for soql, expected := range map[string]string{
+		"SELECT Id FROM Account WHERE Name = 'O\\'Brien' AND Field1__c > 100":        "SELECT Id FROM Account WHERE Name = ? AND Field1__c > ?",
+		"SELECT Id FROM Case WHERE CreatedDate > 2024-01-02T03:04:05Z LIMIT 10":      "SELECT Id FROM Case WHERE CreatedDate > ? LIMIT ?",
+		"SELECT Id FROM Opportunity WHERE Amount >= -1.5 AND CloseDate = 2024-01-02": "SELECT Id FROM Opportunity WHERE Amount >= ? AND CloseDate = ?",
+	} {
+		if redacted := RedactSOQL(soql); redacted != expected {
+			t.Errorf("expected %q, got %q", expected, redacted)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Sforce-Limit-Info", "api-usage=25/5000")
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"totalSize":0,"done":true,"records":[]}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `[{"message":"The requested resource does not exist","errorCode":"NOT_FOUND"}]`)
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	client := simpleforce.NewClient(server.URL, simpleforce.DefaultClientID, simpleforce.DefaultAPIVersion,
+		simpleforce.WithMiddleware(Middleware(
+			WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+			WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		)))
+	client.SetSidLoc("__SESSION_ID__", server.URL)
+
+	if _, err := client.Query("SELECT Id FROM Case WHERE Subject = 'secret'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SObject("Case").Set("Id", "500").Delete(); err == nil {
+		t.Fatal("expected delete to fail")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(ended))
+	}
+	query := attributes(ended[0].Attributes())
+	if ended[0].Name() != "Salesforce Query" || query[SOQLKey] != "SELECT Id FROM Case WHERE Subject = ?" ||
+		query[httpStatusCodeKey] != "200" || query[APIUsedKey] != "25" || query[APIMaxKey] != "5000" {
+		t.Errorf("unexpected query span %s %v", ended[0].Name(), query)
+	}
+	del := attributes(ended[1].Attributes())
+	if ended[1].Status().Code != codes.Error || del[SObjectTypeKey] != "Case" || del[ErrorCodeKey] != "NOT_FOUND" ||
+		del[httpStatusCodeKey] != "404" {
+		t.Errorf("unexpected delete span %v %v", ended[1].Status(), del)
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = true
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && len(sum.DataPoints) != 2 {
+				t.Errorf("expected 2 request data points, got %d", len(sum.DataPoints))
+			}
+		}
+	}
+	if !found["simpleforce.requests"] || !found["simpleforce.request.duration"] {
+		t.Errorf("missing metrics %v", found)
+	}
+}
+
+func TestMiddleware_Download(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "file content")
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	client := simpleforce.NewClient(server.URL, simpleforce.DefaultClientID, simpleforce.DefaultAPIVersion,
+		simpleforce.WithMiddleware(Middleware(
+			WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		)))
+	client.SetSidLoc("__SESSION_ID__", server.URL)
+
+	// The span must still be open while the body is transferred.
+	w := &spanCheckingWriter{spans: spans}
+	if err := client.DownloadAttachmentTo(context.Background(), "00P000000000001", w); err != nil {
+		t.Fatal(err)
+	}
+	if w.ended != 0 {
+		t.Errorf("expected the span to end after the body was read, %d spans ended before", w.ended)
+	}
+	if ended := spans.Ended(); len(ended) != 1 || ended[0].Name() != "Salesforce Download" {
+		t.Errorf("unexpected spans %v", ended)
+	}
+}
+
+type spanCheckingWriter struct {
+	spans *tracetest.SpanRecorder
+	ended int
+}
+
+func (w *spanCheckingWriter) Write(p []byte) (int, error) {
+	w.ended += len(w.spans.Ended())
+	return len(p), nil
+}
+
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]string {
+	m := make(map[attribute.Key]string)
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.Emit()
+	}
+	return m
+}
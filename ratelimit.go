@@ -0,0 +1,144 @@
+package simpleforce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit configures client-side throttling of the requests made by a client, to stay below the request and
+// concurrent long-running request limits of the org. Zero values disable the respective limit.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which tokens are added to the bucket; every request takes one token.
+	RequestsPerSecond float64
+	// Burst is the size of the token bucket, i.e. how many requests can be made at once after a quiet period. It
+	// defaults to 1 if RequestsPerSecond is set.
+	Burst int
+	// MaxInFlight is the maximum number of requests in flight at the same time.
+	MaxInFlight int
+}
+
+// RateLimitStats describes the requests throttled by the rate limit of a client.
+type RateLimitStats struct {
+	// Requests is the total number of requests made.
+	Requests int64
+	// InFlight is the number of requests currently in flight.
+	InFlight int
+	// Waiting is the number of requests currently waiting for the rate limit.
+	Waiting int
+	// TotalWait is the total time requests have spent waiting for the rate limit.
+	TotalWait time.Duration
+}
+
+// SetRateLimit sets the rate limit of the client. The limit is shared with the Tooling view of the client and applies
+// to all requests, including downloads and logins. Requests waiting for the previous limit are not affected.
+func (client *Client) SetRateLimit(limit RateLimit) {
+	client.limiter.configure(limit)
+}
+
+// RateLimitStats returns the current statistics of the rate limit of the client.
+func (client *Client) RateLimitStats() RateLimitStats {
+	client.limiter.mu.Lock()
+	defer client.limiter.mu.Unlock()
+	return client.limiter.stats
+}
+
+// rateLimiter combines a token bucket with a semaphore limiting the requests in flight.
+type rateLimiter struct {
+	mu       sync.Mutex
+	limit    RateLimit
+	tokens   float64
+	last     time.Time
+	inFlight chan struct{}
+	stats    RateLimitStats
+}
+
+func (limiter *rateLimiter) configure(limit RateLimit) {
+	if limit.RequestsPerSecond > 0 && limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.limit = limit
+	limiter.tokens = float64(limit.Burst)
+	limiter.last = time.Now()
+	limiter.inFlight = nil
+	if limit.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+}
+
+// acquire waits until a request may be made according to the rate limit, and returns a function that must be called
+// once the request has completed.
+func (limiter *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	limiter.mu.Lock()
+	delay := limiter.reserve(start)
+	inFlight := limiter.inFlight
+	limiter.stats.Waiting++
+	limiter.mu.Unlock()
+
+	err := limiter.wait(ctx, delay, inFlight)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.stats.Waiting--
+	limiter.stats.TotalWait += time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	limiter.stats.Requests++
+	limiter.stats.InFlight++
+
+	return func() {
+		if inFlight != nil {
+			<-inFlight
+		}
+		limiter.mu.Lock()
+		limiter.stats.InFlight--
+		limiter.mu.Unlock()
+	}, nil
+}
+
+// reserve takes a token from the bucket and returns how long to wait until it is available. The bucket may go into
+// debt, so that concurrent requests are spaced out evenly. mu must be held.
+func (limiter *rateLimiter) reserve(now time.Time) time.Duration {
+	rate := limiter.limit.RequestsPerSecond
+	if rate <= 0 {
+		return 0
+	}
+
+	limiter.tokens += now.Sub(limiter.last).Seconds() * rate
+	if limiter.tokens > float64(limiter.limit.Burst) {
+		limiter.tokens = float64(limiter.limit.Burst)
+	}
+	limiter.last = now
+	limiter.tokens--
+	if limiter.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-limiter.tokens / rate * float64(time.Second))
+}
+
+// wait sleeps for delay and then takes a slot of the inFlight semaphore, if any.
+func (limiter *rateLimiter) wait(ctx context.Context, delay time.Duration, inFlight chan struct{}) error {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if inFlight != nil {
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
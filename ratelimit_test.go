@@ -0,0 +1,77 @@
+package simpleforce
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_RateLimitMaxInFlight(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `{"totalSize":0,"done":true,"records":[]}`)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+	client.SetRateLimit(RateLimit{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Query("SELECT Id FROM Case"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+	stats := client.RateLimitStats()
+	if stats.Requests != 6 || stats.InFlight != 0 || stats.Waiting != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRateLimiter_tokenBucket(t *testing.T) {
+	limiter := &rateLimiter{}
+	limiter.configure(RateLimit{RequestsPerSecond: 100, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// Two requests are allowed by the burst, the other two have to wait 10ms each.
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.configure(RateLimit{RequestsPerSecond: 0.001})
+	limiter.acquire(ctx)
+	if _, err := limiter.acquire(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package simpleforce
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ChildRecords returns the records of a child relationship queried with a subquery, e.g. "Contacts" for
+// "SELECT Id, (SELECT Id, Name FROM Contacts) FROM Account". The records have the client of the SObject attached. If
+// the subquery returned more records than fit in a single page, the result is not Done, and the remaining pages can be
+// fetched with NextPage. An empty result is returned if the subquery matched no records, and nil if the relationship
+// was not queried.
+func (obj *SObject) ChildRecords(relationshipName string) *QueryResult {
+	value, ok := (*obj)[relationshipName]
+	if !ok {
+		return nil
+	}
+	result := &QueryResult{Done: true}
+	if value != nil {
+		children, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		// Decode the nested result like the response of a query, so that records are SObjects.
+		data, err := json.Marshal(children)
+		if err != nil {
+			obj.client().logln("failed to convert child records to json,", err)
+			return nil
+		}
+		if client := obj.client(); client != nil {
+			err = client.unmarshal(data, result)
+		} else {
+			err = json.Unmarshal(data, result)
+		}
+		if err != nil {
+			obj.client().logln("failed to decode child records,", err)
+			return nil
+		}
+	}
+	result.setClient(obj.client())
+	return result
+}
+
+// NextPage fetches the next page of a query result, following NextRecordsURL. This works for the results of Query as
+// well as for the child records returned by ChildRecords.
+func (result *QueryResult) NextPage() (*QueryResult, error) {
+	if result.Done || result.NextRecordsURL == "" {
+		return nil, errors.New("no more query results")
+	}
+	if result.client == nil {
+		return nil, ErrAuthentication
+	}
+	return result.client.Query(result.NextRecordsURL)
+}
+
+// setClient attaches client to the result and its records.
+func (result *QueryResult) setClient(client *Client) {
+	result.client = client
+	for idx := range result.Records {
+		result.Records[idx].setClient(client)
+	}
+}
@@ -0,0 +1,66 @@
+package simpleforce
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSObject_ChildRecords(t *testing.T) {
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/query/01gChild-2") {
+			fmt.Fprint(w, `{"totalSize":3,"done":true,"records":[
+				{"attributes":{"type":"Contact","url":"/services/data/v54.0/sobjects/Contact/003C"},"Id":"003C","Name":"Carol"}
+			]}`)
+			return
+		}
+		fmt.Fprint(w, `{"totalSize":2,"done":true,"records":[
+			{"attributes":{"type":"Account","url":"/services/data/v54.0/sobjects/Account/001A"},"Id":"001A","Contacts":{
+				"totalSize":3,"done":false,"nextRecordsUrl":"/services/data/v54.0/query/01gChild-2","records":[
+					{"attributes":{"type":"Contact","url":"/services/data/v54.0/sobjects/Contact/003A"},"Id":"003A","Name":"Alice"},
+					{"attributes":{"type":"Contact","url":"/services/data/v54.0/sobjects/Contact/003B"},"Id":"003B","Name":"Bob"}
+				]}},
+			{"attributes":{"type":"Account","url":"/services/data/v54.0/sobjects/Account/001B"},"Id":"001B","Contacts":null}
+		]}`)
+	})
+
+	result, err := client.Query("SELECT Id, (SELECT Id, Name FROM Contacts) FROM Account")
+	if err != nil || len(result.Records) != 2 {
+		t.Fatalf("unexpected result %v, %v", result, err)
+	}
+
+	contacts := result.Records[0].ChildRecords("Contacts")
+	if contacts == nil || contacts.TotalSize != 3 || contacts.Done || len(contacts.Records) != 2 {
+		t.Fatalf("unexpected child records %+v", contacts)
+	}
+	var names []string
+	for {
+		for _, contact := range contacts.Records {
+			if contact.client() != client || contact.Type() != "Contact" {
+				t.Errorf("unexpected contact %v", contact)
+			}
+			names = append(names, contact.StringField("Name"))
+		}
+		if contacts.Done {
+			break
+		}
+		contacts, err = contacts.NextPage()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fmt.Sprint(names) != "[Alice Bob Carol]" {
+		t.Errorf("unexpected names %v", names)
+	}
+	if _, err = contacts.NextPage(); err == nil {
+		t.Error("expected an error after the last page")
+	}
+
+	if empty := result.Records[1].ChildRecords("Contacts"); empty == nil || !empty.Done || len(empty.Records) != 0 {
+		t.Errorf("expected an empty result for a null relationship, got %+v", empty)
+	}
+	if missing := result.Records[1].ChildRecords("Opportunities"); missing != nil {
+		t.Errorf("expected nil for a relationship that was not queried, got %+v", missing)
+	}
+}
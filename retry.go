@@ -0,0 +1,132 @@
+package simpleforce
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy configures how requests failing with transient errors are retried. Requests that were rejected by
+// Salesforce, i.e. 503 responses and errors with one of the RetryableErrorCodes, are retried regardless of the method.
+// Requests failing without a response, e.g. on a connection reset, may have been processed already and are only
+// retried if the method is idempotent.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff and MaxBackoff bound the delay before a retry. The delay doubles with every attempt, with a random
+	// jitter of up to half the delay. A Retry-After header sent by Salesforce takes precedence if it is longer,
+	// but the request is not retried if Retry-After exceeds MaxBackoff. A zero MaxBackoff means no upper bound.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// RetryableErrorCodes lists the error codes that are retried if any of the errors returned by Salesforce has one.
+	RetryableErrorCodes []string
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with a sensible configuration for most applications.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		RetryableErrorCodes: []string{
+			"UNABLE_TO_LOCK_ROW",
+			"REQUEST_LIMIT_EXCEEDED",
+			"SERVER_UNAVAILABLE",
+		},
+	}
+}
+
+// SetRetryPolicy sets the retry policy of the client. Requests are not retried if policy is nil, which is the default.
+func (client *Client) SetRetryPolicy(policy *RetryPolicy) {
+	client.retryPolicy = policy
+}
+
+// shouldRetry returns whether a request that failed with err on the given attempt should be retried, and how long to
+// wait before doing so.
+func (policy *RetryPolicy) shouldRetry(method string, attempt int, header http.Header, err error) (bool, time.Duration) {
+	if policy == nil || err == nil || attempt >= policy.MaxAttempts {
+		return false, 0
+	}
+
+	var sfErr SalesforceError
+	if errors.As(err, &sfErr) {
+		if !policy.isRetryable(sfErr) {
+			return false, 0
+		}
+	} else if !isIdempotent(method) {
+		return false, 0
+	}
+
+	backoff := policy.backoff(attempt)
+	if retryAfter := parseRetryAfter(header); retryAfter > backoff {
+		if policy.MaxBackoff > 0 && retryAfter > policy.MaxBackoff {
+			// Give up rather than block for longer than the policy allows.
+			return false, 0
+		}
+		backoff = retryAfter
+	}
+	return true, backoff
+}
+
+// isRetryable returns whether the error response indicates that the request was not processed and can be retried.
+func (policy *RetryPolicy) isRetryable(err SalesforceError) bool {
+	if err.HttpCode == http.StatusServiceUnavailable {
+		return true
+	}
+	for _, code := range policy.RetryableErrorCodes {
+		if err.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the retry following attempt.
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := policy.MinBackoff
+	for i := 1; i < attempt && backoff < math.MaxInt64/2; i++ {
+		backoff *= 2
+	}
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// streamingBody marks a request body that is streamed and can't be buffered to be resent, so the request is never
+// retried.
+type streamingBody struct {
+	io.Reader
+}
@@ -0,0 +1,124 @@
+package simpleforce
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	var bodies []string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"message":"unable to obtain exclusive access to this record","errorCode":"UNABLE_TO_LOCK_ROW"}]`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"500000000000001","success":true,"errors":[]}`)
+	})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	obj := client.SObject("Case").Set("Subject", "Retried")
+	if obj.Create() == nil {
+		t.Fatal(obj.Err())
+	}
+	if len(bodies) != 3 || bodies[2] != bodies[0] || bodies[0] != `{"Subject":"Retried"}` {
+		t.Errorf("unexpected requests %q", bodies)
+	}
+}
+
+func TestClient_RetryPolicyExhausted(t *testing.T) {
+	attempts := 0
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	_, err := client.Query("SELECT Id FROM Case")
+	var sfErr SalesforceError
+	if !errors.As(err, &sfErr) || sfErr.HttpCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected error %v", err)
+	}
+	if attempts != 4 {
+		t.Errorf("expected 4 attempts, got %d", attempts)
+	}
+}
+
+func TestClient_RetryPolicyCancelled(t *testing.T) {
+	attempts := 0
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	policy := testRetryPolicy()
+	policy.MinBackoff = time.Minute
+	policy.MaxBackoff = time.Minute
+	client.SetRetryPolicy(policy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := client.WithContext(ctx).Query("SELECT Id FROM Case"); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second || attempts != 1 {
+		t.Errorf("expected the retry to be cancelled, got %d attempts in %v", attempts, elapsed)
+	}
+}
+
+func TestRetryPolicy_shouldRetry(t *testing.T) {
+	policy := testRetryPolicy()
+	connErr := errors.New("connection reset by peer")
+
+	if retry, _ := policy.shouldRetry(http.MethodGet, 1, nil, connErr); !retry {
+		t.Error("expected GET to be retried on connection errors")
+	}
+	if retry, _ := policy.shouldRetry(http.MethodPost, 1, nil, connErr); retry {
+		t.Error("expected POST not to be retried on connection errors")
+	}
+	if retry, _ := policy.shouldRetry(http.MethodGet, 1, nil, SalesforceError{HttpCode: 400, ErrorCode: "MALFORMED_QUERY"}); retry {
+		t.Error("expected MALFORMED_QUERY not to be retried")
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", "2")
+	limitErr := SalesforceError{HttpCode: 403, ErrorCode: "REQUEST_LIMIT_EXCEEDED"}
+	if retry, _ := policy.shouldRetry(http.MethodPost, 1, header, limitErr); retry {
+		t.Error("expected no retry when Retry-After exceeds MaxBackoff")
+	}
+	policy.MaxBackoff = 5 * time.Second
+	if retry, backoff := policy.shouldRetry(http.MethodPost, 1, header, limitErr); !retry || backoff != 2*time.Second {
+		t.Errorf("expected retry after 2s, got %v %v", retry, backoff)
+	}
+
+	// Without MaxBackoff, the backoff and Retry-After are not bounded.
+	unbounded := &RetryPolicy{MaxAttempts: 4, MinBackoff: time.Second, RetryableErrorCodes: []string{"REQUEST_LIMIT_EXCEEDED"}}
+	header.Set("Retry-After", "120")
+	if retry, backoff := unbounded.shouldRetry(http.MethodPost, 1, header, limitErr); !retry || backoff != 2*time.Minute {
+		t.Errorf("expected retry after 2m, got %v %v", retry, backoff)
+	}
+	if retry, backoff := unbounded.shouldRetry(http.MethodGet, 3, nil, connErr); !retry || backoff < 2*time.Second || backoff > 4*time.Second {
+		t.Errorf("expected retry after 2s to 4s, got %v %v", retry, backoff)
+	}
+
+	var nilPolicy *RetryPolicy
+	if retry, _ := nilPolicy.shouldRetry(http.MethodGet, 1, nil, connErr); retry {
+		t.Error("expected no retries without a policy")
+	}
+}
@@ -0,0 +1,613 @@
+// Package simpleforcetest provides an in-memory fake Salesforce server, so that code using simpleforce can be tested
+// end-to-end without an org.
+//
+// The server emulates the SOAP login, the sobjects resources to create, get, update, upsert and delete records by ID
+// or external ID, describe, and a subset of SOQL queries paginated with nextRecordsUrl:
+//
+//	server := simpleforcetest.NewServer()
+//	defer server.Close()
+//
+//	server.Insert("Account", map[string]interface{}{"Name": "Acme"})
+//	client := server.NewClient()
+//	result, err := client.Query("SELECT Id, Name FROM Account WHERE Name LIKE 'Ac%' ORDER BY Name LIMIT 10")
+package simpleforcetest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simpleforce/simpleforce"
+)
+
+const (
+	// DefaultUsername and DefaultPassword are the credentials accepted by a server created without WithCredentials.
+	DefaultUsername = "user@example.com"
+	DefaultPassword = "password"
+
+	// DefaultBatchSize is the number of records returned per page of query results.
+	DefaultBatchSize = 2000
+
+	organizationID = "00D000000000001AAA"
+	userID         = "005000000000001AAA"
+)
+
+// keyPrefixes are the ID prefixes of common standard objects. Other types get prefixes of custom objects.
+var keyPrefixes = map[string]string{
+	"Account":        "001",
+	"Contact":        "003",
+	"Opportunity":    "006",
+	"Lead":           "00Q",
+	"Case":           "500",
+	"User":           "005",
+	"Attachment":     "00P",
+	"Document":       "015",
+	"ContentVersion": "068",
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithCredentials sets the username and password accepted by the SOAP login. The security token is expected to be
+// appended to the password, as in simpleforce.Client.LoginPassword.
+func WithCredentials(username, password string) Option {
+	return func(server *Server) {
+		server.username = username
+		server.password = password
+	}
+}
+
+// WithBatchSize sets the number of records returned per page of query results.
+func WithBatchSize(batchSize int) Option {
+	return func(server *Server) {
+		server.batchSize = batchSize
+	}
+}
+
+// Server is a fake Salesforce server keeping records in memory. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	username  string
+	password  string
+	sessionID string
+	batchSize int
+
+	mu        sync.Mutex
+	types     []string
+	records   map[string][]map[string]interface{}
+	describes map[string]simpleforce.SObjectMeta
+	cursors   map[string]*cursor
+	lastID    int
+}
+
+// NewServer starts a fake Salesforce server. The caller should call Close when finished, to shut it down.
+func NewServer(opts ...Option) *Server {
+	server := &Server{
+		username:  DefaultUsername,
+		password:  DefaultPassword,
+		sessionID: organizationID + "!FAKE_SESSION_ID",
+		batchSize: DefaultBatchSize,
+		records:   make(map[string][]map[string]interface{}),
+		describes: make(map[string]simpleforce.SObjectMeta),
+		cursors:   make(map[string]*cursor),
+	}
+	for _, opt := range opts {
+		opt(server)
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+// SessionID returns the session ID issued by the server, e.g. to use with simpleforce.Client.SetSidLoc.
+func (server *Server) SessionID() string {
+	return server.sessionID
+}
+
+// NewClient returns a client logged in to the server.
+func (server *Server) NewClient(opts ...simpleforce.ClientOption) *simpleforce.Client {
+	client := simpleforce.NewClient(server.URL, simpleforce.DefaultClientID, simpleforce.DefaultAPIVersion, opts...)
+	client.SetSidLoc(server.sessionID, server.URL)
+	return client
+}
+
+// Insert adds a record of sobjectType and returns its ID. The ID is generated unless fields contain one.
+func (server *Server) Insert(sobjectType string, fields map[string]interface{}) string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return server.insert(sobjectType, fields)
+}
+
+// Record returns a copy of the record of sobjectType with the given ID, or nil if it does not exist.
+func (server *Server) Record(sobjectType, id string) map[string]interface{} {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if _, record := server.find(sobjectType, "Id", id); record != nil {
+		return copyRecord(record)
+	}
+	return nil
+}
+
+// Records returns copies of all records of sobjectType, in the order they were created.
+func (server *Server) Records(sobjectType string) []map[string]interface{} {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	var records []map[string]interface{}
+	for _, record := range server.records[server.typeName(sobjectType)] {
+		records = append(records, copyRecord(record))
+	}
+	return records
+}
+
+// SetDescribe sets the metadata returned when describing sobjectType, instead of the metadata derived from its
+// records. It also makes the type known to the server if it has no records yet.
+func (server *Server) SetDescribe(sobjectType string, meta simpleforce.SObjectMeta) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	sobjectType = server.addType(sobjectType)
+	server.describes[sobjectType] = meta
+}
+
+// serveHTTP routes requests to the emulated resources.
+func (server *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/services/Soap/u/") && r.Method == http.MethodPost {
+		server.login(w, r)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+server.sessionID {
+		writeError(w, http.StatusUnauthorized, "INVALID_SESSION_ID", "Session expired or invalid")
+		return
+	}
+
+	// Paths look like /services/data/v54.0/{resource}.
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "services" || parts[1] != "data" {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+	version, resource := parts[2], parts[3:]
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	switch {
+	case resource[0] == "query" && len(resource) == 1 && r.Method == http.MethodGet:
+		server.query(w, version, r.URL.Query().Get("q"), queryBatchSize(r.Header.Get("Sforce-Query-Options")))
+	case resource[0] == "query" && len(resource) == 2 && r.Method == http.MethodGet:
+		server.queryMore(w, version, resource[1])
+	case resource[0] == "sobjects":
+		server.sobjects(w, r, version, resource[1:])
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+// login emulates the SOAP login call.
+func (server *Server) login(w http.ResponseWriter, r *http.Request) {
+	var login struct {
+		Username string `xml:"Body>login>username"`
+		Password string `xml:"Body>login>password"`
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	if err := xml.Unmarshal(body, &login); err != nil || login.Username != server.username ||
+		login.Password != server.password {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><soapenv:Fault>
+<faultcode>INVALID_LOGIN</faultcode>
+<faultstring>INVALID_LOGIN: Invalid username, password, security token; or user locked out.</faultstring>
+</soapenv:Fault></soapenv:Body></soapenv:Envelope>`)
+		return
+	}
+
+	version := strings.TrimPrefix(r.URL.Path, "/services/Soap/u/")
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><loginResponse><result>
+<serverUrl>%s/services/Soap/u/%s/%s</serverUrl><sessionId>%s</sessionId><userId>%s</userId>
+<userInfo><userEmail>%s</userEmail><userFullName>Test User</userFullName><userName>%s</userName></userInfo>
+</result></loginResponse></soapenv:Body></soapenv:Envelope>`,
+		server.URL, version, organizationID, server.sessionID, userID, server.username, server.username)
+}
+
+// cursor holds the results of a query for the following pages.
+type cursor struct {
+	records   []map[string]interface{}
+	batchSize int
+}
+
+// queryBatchSize parses the batch size of a Sforce-Query-Options header, e.g. "batchSize=500", or returns 0 if there
+// is none.
+func queryBatchSize(queryOptions string) int {
+	for _, option := range strings.Split(queryOptions, ",") {
+		option = strings.TrimSpace(option)
+		if strings.HasPrefix(option, "batchSize=") {
+			batchSize, _ := strconv.Atoi(strings.TrimPrefix(option, "batchSize="))
+			return batchSize
+		}
+	}
+	return 0
+}
+
+// query runs a SOQL query and writes the first page of results. Unlike Salesforce, any positive batch size is
+// accepted, to keep tests small.
+func (server *Server) query(w http.ResponseWriter, version, soql string, batchSize int) {
+	q, err := parseQuery(soql)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "MALFORMED_QUERY", err.Error())
+		return
+	}
+	sobjectType := server.typeName(q.sobjectType)
+	if sobjectType == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_TYPE",
+			fmt.Sprintf("sObject type '%s' is not supported.", q.sobjectType))
+		return
+	}
+
+	matched := q.execute(server.records[sobjectType])
+	if q.count {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"totalSize": len(matched), "done": true, "records": []interface{}{}})
+		return
+	}
+	records := make([]map[string]interface{}, 0, len(matched))
+	for _, record := range matched {
+		records = append(records, project(version, sobjectType, record, q.fields))
+	}
+	if batchSize <= 0 {
+		batchSize = server.batchSize
+	}
+	server.writePage(w, version, server.newLocator(), &cursor{records: records, batchSize: batchSize}, 0)
+}
+
+// queryMore writes the next page of the results of a query.
+func (server *Server) queryMore(w http.ResponseWriter, version, locator string) {
+	// Locators look like {cursor}-{offset}.
+	var c *cursor
+	offset := -1
+	if i := strings.LastIndex(locator, "-"); i > 0 {
+		c = server.cursors[locator[:i]]
+		offset, _ = strconv.Atoi(locator[i+1:])
+		locator = locator[:i]
+	}
+	if c == nil || offset < 0 || offset > len(c.records) {
+		writeError(w, http.StatusBadRequest, "INVALID_QUERY_LOCATOR", "invalid query locator")
+		return
+	}
+	server.writePage(w, version, locator, c, offset)
+}
+
+// writePage writes the page of the results of a query starting at offset, and keeps the results for the next page if
+// there is one.
+func (server *Server) writePage(w http.ResponseWriter, version, locator string, c *cursor, offset int) {
+	end := offset + c.batchSize
+	if end >= len(c.records) {
+		end = len(c.records)
+		delete(server.cursors, locator)
+	} else {
+		server.cursors[locator] = c
+	}
+
+	page := map[string]interface{}{
+		"totalSize": len(c.records),
+		"done":      end == len(c.records),
+		"records":   c.records[offset:end],
+	}
+	if end < len(c.records) {
+		page["nextRecordsUrl"] = fmt.Sprintf("/services/data/%s/query/%s-%d", version, locator, end)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// sobjects emulates the sobjects resources.
+func (server *Server) sobjects(w http.ResponseWriter, r *http.Request, version string, path []string) {
+	if len(path) == 0 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method '"+r.Method+"' not allowed.")
+			return
+		}
+		var sobjects []interface{}
+		for _, sobjectType := range server.types {
+			sobjects = append(sobjects, map[string]interface{}{"name": sobjectType, "keyPrefix": server.keyPrefix(sobjectType)})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"sobjects": sobjects})
+		return
+	}
+
+	// Types become known once a record is created, so unknown types simply have no records.
+	sobjectType := server.typeName(path[0])
+	if sobjectType == "" {
+		sobjectType = path[0]
+	}
+
+	var fields map[string]interface{}
+	if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err != nil {
+			writeError(w, http.StatusBadRequest, "JSON_PARSER_ERROR", err.Error())
+			return
+		}
+		if fields == nil {
+			writeError(w, http.StatusBadRequest, "JSON_PARSER_ERROR", "Expected a JSON object in the request body.")
+			return
+		}
+		if _, ok := fields["Id"]; ok && len(path) > 1 {
+			writeError(w, http.StatusBadRequest, "INVALID_FIELD_FOR_INSERT_UPDATE", "Unable to create/update fields: Id.")
+			return
+		}
+		normalizeNumbers(fields)
+	}
+
+	switch {
+	case len(path) == 1 && r.Method == http.MethodPost:
+		id := server.insert(sobjectType, fields)
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"id": id, "success": true, "errors": []interface{}{}})
+	case len(path) == 2 && path[1] == "describe" && r.Method == http.MethodGet:
+		if server.typeName(sobjectType) == "" {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+			return
+		}
+		writeJSON(w, http.StatusOK, server.describe(sobjectType))
+	case len(path) == 2:
+		server.record(w, r, version, sobjectType, "Id", path[1], fields)
+	case len(path) == 3:
+		server.record(w, r, version, sobjectType, path[1], path[2], fields)
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+	}
+}
+
+// record gets, updates, upserts or deletes the record whose field has value.
+func (server *Server) record(w http.ResponseWriter, r *http.Request, version, sobjectType, field, value string,
+	fields map[string]interface{}) {
+	i, record := server.find(sobjectType, field, value)
+	if record == nil && !(r.Method == http.MethodPatch && field != "Id") {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+	if i == -2 {
+		writeError(w, http.StatusMultipleChoices, "MULTIPLE_RECORDS",
+			fmt.Sprintf("More than one record found for external ID field %s", field))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		var selected []string
+		if list := r.URL.Query().Get("fields"); list != "" {
+			selected = strings.Split(list, ",")
+		}
+		writeJSON(w, http.StatusOK, project(version, sobjectType, record, selected))
+	case http.MethodPatch:
+		if record == nil {
+			fields[field] = value
+			id := server.insert(sobjectType, fields)
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"id": id, "success": true, "errors": []interface{}{}})
+			return
+		}
+		for key, val := range fields {
+			record[key] = val
+		}
+		touch(record)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		records := server.records[sobjectType]
+		server.records[sobjectType] = append(records[:i:i], records[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "HTTP Method '"+r.Method+"' not allowed.")
+	}
+}
+
+// describe returns the metadata of sobjectType, derived from its records unless set with SetDescribe.
+func (server *Server) describe(sobjectType string) simpleforce.SObjectMeta {
+	if meta, ok := server.describes[sobjectType]; ok {
+		return meta
+	}
+
+	fieldTypes := map[string]string{"Id": "id"}
+	names := []string{"Id"}
+	for _, record := range server.records[sobjectType] {
+		for name, value := range record {
+			if _, ok := fieldTypes[name]; ok && value == nil {
+				continue
+			}
+			if _, ok := fieldTypes[name]; !ok {
+				names = append(names, name)
+			}
+			fieldTypes[name] = fieldType(name, value)
+		}
+	}
+
+	sort.Strings(names[1:])
+	fields := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, map[string]interface{}{
+			"name":   name,
+			"label":  name,
+			"type":   fieldTypes[name],
+			"custom": strings.HasSuffix(name, "__c"),
+		})
+	}
+	return simpleforce.SObjectMeta{
+		"name":      sobjectType,
+		"label":     sobjectType,
+		"keyPrefix": server.keyPrefix(sobjectType),
+		"custom":    strings.HasSuffix(sobjectType, "__c"),
+		"fields":    fields,
+	}
+}
+
+// fieldType guesses the describe type of a field from its value.
+func fieldType(name string, value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "double"
+	case map[string]interface{}:
+		return "address"
+	}
+	if strings.HasSuffix(name, "Date") {
+		return "datetime"
+	}
+	return "string"
+}
+
+// insert adds a record and returns its ID. mu must be held.
+func (server *Server) insert(sobjectType string, fields map[string]interface{}) string {
+	sobjectType = server.addType(sobjectType)
+	record := copyRecord(fields)
+	id, _ := record["Id"].(string)
+	if id == "" {
+		server.lastID++
+		id = fmt.Sprintf("%s%012dAAA", server.keyPrefix(sobjectType), server.lastID)
+		record["Id"] = id
+	}
+	if _, ok := record["CreatedDate"]; !ok {
+		record["CreatedDate"] = now()
+	}
+	touch(record)
+	server.records[sobjectType] = append(server.records[sobjectType], record)
+	return id
+}
+
+// find returns the index of the record of sobjectType whose field has value, and the record itself. The index is -1
+// if no record matches, and -2 if several records match. mu must be held.
+func (server *Server) find(sobjectType, field, value string) (int, map[string]interface{}) {
+	index, found := -1, map[string]interface{}(nil)
+	for i, record := range server.records[server.typeName(sobjectType)] {
+		v := lookupField(record, field)
+		if v == nil || !strings.EqualFold(fmt.Sprint(v), value) {
+			continue
+		}
+		if found != nil {
+			return -2, found
+		}
+		index, found = i, record
+	}
+	return index, found
+}
+
+// addType makes sobjectType known to the server, and returns its name as first used. mu must be held.
+func (server *Server) addType(sobjectType string) string {
+	if name := server.typeName(sobjectType); name != "" {
+		return name
+	}
+	server.types = append(server.types, sobjectType)
+	return sobjectType
+}
+
+// typeName returns the name of a known type, ignoring case, or an empty string if the type is unknown. mu must be
+// held.
+func (server *Server) typeName(sobjectType string) string {
+	for _, name := range server.types {
+		if strings.EqualFold(name, sobjectType) {
+			return name
+		}
+	}
+	return ""
+}
+
+// keyPrefix returns the ID prefix of a known type. mu must be held.
+func (server *Server) keyPrefix(sobjectType string) string {
+	if prefix, ok := keyPrefixes[sobjectType]; ok {
+		return prefix
+	}
+	for i, name := range server.types {
+		if name == sobjectType {
+			return fmt.Sprintf("a%02d", i)
+		}
+	}
+	return ""
+}
+
+// newLocator returns a new query locator. mu must be held.
+func (server *Server) newLocator() string {
+	server.lastID++
+	return fmt.Sprintf("01g%012dAAA", server.lastID)
+}
+
+// project returns the record with its attributes and the given fields, or all fields if none are given.
+func project(version, sobjectType string, record map[string]interface{}, fields []string) map[string]interface{} {
+	result := map[string]interface{}{
+		"attributes": map[string]interface{}{
+			"type": sobjectType,
+			"url":  fmt.Sprintf("/services/data/%s/sobjects/%s/%s", version, sobjectType, record["Id"]),
+		},
+	}
+	if len(fields) == 0 {
+		for key, value := range record {
+			result[key] = value
+		}
+		return result
+	}
+	for _, field := range fields {
+		// Relationship fields are nested, e.g. Account.Name becomes {"Account": {"Name": ...}}.
+		names := strings.Split(field, ".")
+		parent := result
+		for _, name := range names[:len(names)-1] {
+			child, ok := parent[name].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[name] = child
+			}
+			parent = child
+		}
+		parent[names[len(names)-1]] = lookup(record, field)
+	}
+	return result
+}
+
+// normalizeNumbers converts the json.Number values decoded from a request to float64, as if decoded without
+// UseNumber, unless they do not fit.
+func normalizeNumbers(fields map[string]interface{}) {
+	for key, value := range fields {
+		if n, ok := value.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				fields[key] = f
+			}
+		}
+	}
+}
+
+// touch updates the modification timestamps of a record.
+func touch(record map[string]interface{}) {
+	record["LastModifiedDate"] = now()
+	record["SystemModstamp"] = record["LastModifiedDate"]
+}
+
+func now() string {
+	return time.Now().UTC().Format(simpleforce.DateTimeLayout)
+}
+
+func copyRecord(record map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		copied[key] = value
+	}
+	return copied
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format of the REST API.
+func writeError(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	writeJSON(w, statusCode, []map[string]interface{}{{"errorCode": errorCode, "message": message}})
+}
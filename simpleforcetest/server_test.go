@@ -0,0 +1,179 @@
+package simpleforcetest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/simpleforce/simpleforce"
+)
+
+func TestServer_Login(t *testing.T) {
+	server := NewServer(WithCredentials("admin@example.com", "secret"))
+	defer server.Close()
+
+	client := simpleforce.NewClient(server.URL, simpleforce.DefaultClientID, simpleforce.DefaultAPIVersion)
+	if err := client.LoginPassword("admin@example.com", "wrong", ""); err == nil {
+		t.Error("expected login to fail")
+	}
+	if err := client.LoginPassword("admin@example.com", "sec", "ret"); err != nil {
+		t.Fatal(err)
+	}
+	if client.GetSid() != server.SessionID() || client.GetLoc() != server.URL {
+		t.Errorf("unexpected session %s at %s", client.GetSid(), client.GetLoc())
+	}
+	if _, err := client.Query("SELECT Id FROM Account"); err == nil {
+		t.Error("expected a query of an unknown type to fail")
+	}
+}
+
+func TestServer_CRUD(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.NewClient()
+
+	account := client.SObject("Account").Set("Name", "Acme").Set("NumberOfEmployees", 10).Create()
+	if account == nil || account.ID() == "" {
+		t.Fatal("failed to create account")
+	}
+	if record := server.Record("Account", account.ID()); record["Name"] != "Acme" || record["NumberOfEmployees"] != 10.0 {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	got := client.SObject("Account").GetFields(account.ID(), "Name")
+	if got == nil || got.StringField("Name") != "Acme" || got.InterfaceField("NumberOfEmployees") != nil {
+		t.Errorf("unexpected record %v", got)
+	}
+	if client.SObject("Account").Set("Id", account.ID()).Set("Name", "Acme Inc").Update() == nil {
+		t.Fatal("failed to update account")
+	}
+	if name := server.Record("Account", account.ID())["Name"]; name != "Acme Inc" {
+		t.Errorf("unexpected name %v", name)
+	}
+
+	meta := client.SObject("Account").Describe()
+	if meta == nil || (*meta)["name"] != "Account" || len((*meta)["fields"].([]interface{})) < 3 {
+		t.Errorf("unexpected describe %v", meta)
+	}
+
+	if err := client.SObject("Account").Delete(account.ID()); err != nil {
+		t.Fatal(err)
+	}
+	missing := client.SObject("Account").Get(account.ID())
+	if missing != nil {
+		t.Errorf("expected deleted record to be missing, got %v", missing)
+	}
+	if server.Record("Account", account.ID()) != nil {
+		t.Error("expected record to be deleted")
+	}
+}
+
+func TestServer_Upsert(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.NewClient()
+
+	upsert := func(name string) *simpleforce.SObject {
+		return client.SObject("Contact").
+			Set("ExternalIDField", "Ext__c").
+			Set("Ext__c", "C-1").
+			Set("LastName", name).
+			Upsert()
+	}
+	created := upsert("Doe")
+	if created == nil || created.ID() == "" {
+		t.Fatal("failed to insert contact")
+	}
+	if upsert("Smith") == nil {
+		t.Fatal("failed to update contact")
+	}
+	records := server.Records("Contact")
+	if len(records) != 1 || records[0]["LastName"] != "Smith" || records[0]["Ext__c"] != "C-1" {
+		t.Errorf("unexpected records %v", records)
+	}
+
+	if got := client.SObject("Contact").GetByExternalID("Ext__c", "C-1"); got == nil || got.ID() != created.ID() {
+		t.Errorf("unexpected record %v", got)
+	}
+	if err := client.SObject("Contact").DeleteByExternalID("Ext__c", "C-1"); err != nil {
+		t.Fatal(err)
+	}
+	err := client.SObject("Contact").DeleteByExternalID("Ext__c", "C-1")
+	if !errors.Is(err, simpleforce.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestServer_NullBody(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	u := server.URL + "/services/data/v" + simpleforce.DefaultAPIVersion + "/sobjects/Contact/Ext__c/C-1"
+	req, err := http.NewRequest(http.MethodPatch, u, strings.NewReader("null"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+server.SessionID())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+
+	err = simpleforce.ParseSalesforceError(resp.StatusCode, data)
+	var sfErr simpleforce.SalesforceError
+	if !errors.As(err, &sfErr) || sfErr.HttpCode != http.StatusBadRequest || sfErr.ErrorCode != "JSON_PARSER_ERROR" {
+		t.Errorf("expected JSON_PARSER_ERROR, got %v", err)
+	}
+}
+
+func TestServer_QueryPagination(t *testing.T) {
+	server := NewServer(WithBatchSize(2))
+	defer server.Close()
+	client := server.NewClient()
+	for i := 1; i <= 5; i++ {
+		server.Insert("Case", map[string]interface{}{"Subject": fmt.Sprintf("Case %d", i), "Priority": float64(i % 3)})
+	}
+
+	var subjects []string
+	result, err := client.Query("SELECT Subject FROM Case WHERE Priority != 0 ORDER BY Priority DESC, Subject")
+	for err == nil {
+		if result.TotalSize != 4 {
+			t.Errorf("unexpected total size %d", result.TotalSize)
+		}
+		for _, record := range result.Records {
+			subjects = append(subjects, record.StringField("Subject"))
+		}
+		if result.Done {
+			break
+		}
+		result, err = client.Query(result.NextRecordsURL)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(subjects) != "[Case 2 Case 5 Case 1 Case 4]" {
+		t.Errorf("unexpected subjects %v", subjects)
+	}
+
+	pages := 0
+	cursor := client.QueryCursor("SELECT Id FROM Case", simpleforce.QueryBatchSize(3))
+	for cursor.More() {
+		if _, err = cursor.Next(); err != nil {
+			t.Fatal(err)
+		}
+		pages++
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages of 3 records, got %d", pages)
+	}
+
+	result, err = client.Query("SELECT COUNT() FROM Case")
+	if err != nil || result.TotalSize != 5 || len(result.Records) != 0 {
+		t.Errorf("unexpected count %v, %v", result, err)
+	}
+}
@@ -0,0 +1,591 @@
+package simpleforcetest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// query is a parsed SOQL query of the subset supported by the server:
+//
+//	SELECT field, ... | COUNT() FROM type [WHERE condition] [ORDER BY field [ASC|DESC] [NULLS FIRST|LAST], ...]
+//	[LIMIT n] [OFFSET n]
+//
+// Conditions compare fields with string, number, boolean, null and date literals using =, !=, <>, <, <=, >, >=, LIKE,
+// IN and NOT IN, and can be combined with AND, OR, NOT and parentheses.
+type query struct {
+	fields      []string
+	count       bool
+	sobjectType string
+	where       condition
+	orderBy     []ordering
+	limit       int
+	offset      int
+}
+
+// condition reports whether a record matches a WHERE clause.
+type condition func(record map[string]interface{}) bool
+
+type ordering struct {
+	field      string
+	descending bool
+	nullsLast  bool
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDate
+	tokenOperator
+	tokenComma
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// like is the text of a string literal with the escaped LIKE wildcards \% and \_ kept escaped.
+	like string
+}
+
+// is reports whether the token is the keyword or punctuation text, ignoring case.
+func (tok token) is(text string) bool {
+	return (tok.kind == tokenIdent || tok.kind == tokenOperator) && strings.EqualFold(tok.text, text)
+}
+
+// tokenize splits a SOQL query into tokens.
+func tokenize(soql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(soql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ","})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")"})
+			i++
+		case r == '\'':
+			var sb, like strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					if runes[i] == '%' || runes[i] == '_' {
+						like.WriteRune('\\')
+					}
+				}
+				sb.WriteRune(runes[i])
+				like.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), like: like.String()})
+			i++
+		case unicode.IsDigit(r) || (r == '-' || r == '+') && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			start := i
+			for i++; i < len(runes) && isLiteralRune(runes[i]); i++ {
+			}
+			text := string(runes[start:i])
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				tokens = append(tokens, token{kind: tokenNumber, text: text})
+			} else {
+				tokens = append(tokens, token{kind: tokenDate, text: text})
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i])})
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			for i++; i < len(runes) && strings.ContainsRune("=<>", runes[i]); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// isLiteralRune reports whether r may be part of a number or date literal.
+func isLiteralRune(r rune) bool {
+	return unicode.IsDigit(r) || unicode.IsLetter(r) || strings.ContainsRune(".:+-", r)
+}
+
+// parser is a recursive descent parser of the supported SOQL subset.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(keyword string) error {
+	if tok := p.next(); !tok.is(keyword) {
+		return fmt.Errorf("expected %s, found %q", keyword, tok.text)
+	}
+	return nil
+}
+
+// parseQuery parses a SOQL query.
+func parseQuery(soql string) (*query, error) {
+	tokens, err := tokenize(soql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &query{limit: -1}
+
+	if err = p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.peek().is("COUNT") {
+		p.next()
+		if p.next().kind != tokenLParen || p.next().kind != tokenRParen {
+			return nil, fmt.Errorf("only COUNT() is supported")
+		}
+		q.count = true
+	} else {
+		for {
+			tok := p.next()
+			if tok.kind != tokenIdent {
+				return nil, fmt.Errorf("expected field, found %q", tok.text)
+			}
+			q.fields = append(q.fields, tok.text)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if err = p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return nil, fmt.Errorf("expected sObject type, found %q", tok.text)
+	}
+	q.sobjectType = tok.text
+
+	if p.peek().is("WHERE") {
+		p.next()
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().is("ORDER") {
+		p.next()
+		if err = p.expect("BY"); err != nil {
+			return nil, err
+		}
+		if q.orderBy, err = p.parseOrderBy(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().is("LIMIT") {
+		p.next()
+		if q.limit, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().is("OFFSET") {
+		p.next()
+		if q.offset, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+	}
+	if tok := p.next(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected token %q", tok.text)
+	}
+	return q, nil
+}
+
+func (p *parser) parseInt() (int, error) {
+	tok := p.next()
+	n, err := strconv.Atoi(tok.text)
+	if tok.kind != tokenNumber || err != nil || n < 0 {
+		return 0, fmt.Errorf("expected non-negative integer, found %q", tok.text)
+	}
+	return n, nil
+}
+
+func (p *parser) parseOrderBy() ([]ordering, error) {
+	var orderBy []ordering
+	for {
+		tok := p.next()
+		if tok.kind != tokenIdent {
+			return nil, fmt.Errorf("expected field, found %q", tok.text)
+		}
+		order := ordering{field: tok.text}
+		if p.peek().is("ASC") {
+			p.next()
+		} else if p.peek().is("DESC") {
+			p.next()
+			order.descending = true
+		}
+		// Nulls sort first in ascending and last in descending order by default.
+		order.nullsLast = order.descending
+		if p.peek().is("NULLS") {
+			p.next()
+			switch tok := p.next(); {
+			case tok.is("FIRST"):
+				order.nullsLast = false
+			case tok.is("LAST"):
+				order.nullsLast = true
+			default:
+				return nil, fmt.Errorf("expected FIRST or LAST, found %q", tok.text)
+			}
+		}
+		orderBy = append(orderBy, order)
+		if p.peek().kind != tokenComma {
+			return orderBy, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(record map[string]interface{}) bool { return l(record) || right(record) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(record map[string]interface{}) bool { return l(record) && right(record) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	switch {
+	case p.peek().is("NOT"):
+		p.next()
+		cond, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(record map[string]interface{}) bool { return !cond(record) }, nil
+	case p.peek().kind == tokenLParen:
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ), found %q", tok.text)
+		}
+		return cond, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (condition, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return nil, fmt.Errorf("expected field, found %q", tok.text)
+	}
+	field := tok.text
+
+	negate := false
+	if p.peek().is("NOT") {
+		p.next()
+		negate = true
+	}
+	op := p.next()
+	switch {
+	case op.is("IN"):
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return func(record map[string]interface{}) bool {
+			value := lookup(record, field)
+			for _, v := range values {
+				if c, ok := compare(value, v); ok && c == 0 {
+					return !negate
+				}
+			}
+			return negate
+		}, nil
+	case negate:
+		return nil, fmt.Errorf("expected IN after NOT, found %q", op.text)
+	case op.is("LIKE"):
+		tok := p.next()
+		if tok.kind != tokenString {
+			return nil, fmt.Errorf("expected string after LIKE, found %q", tok.text)
+		}
+		pattern := regexp.MustCompile("(?is)^" + likePattern(tok.like) + "$")
+		return func(record map[string]interface{}) bool {
+			s, ok := lookup(record, field).(string)
+			return ok && pattern.MatchString(s)
+		}, nil
+	case op.kind != tokenOperator:
+		return nil, fmt.Errorf("expected operator, found %q", op.text)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	var accept func(c int) bool
+	switch op.text {
+	case "=":
+		accept = func(c int) bool { return c == 0 }
+	case "!=", "<>":
+		accept = func(c int) bool { return c != 0 }
+	case "<":
+		accept = func(c int) bool { return c < 0 }
+	case "<=":
+		accept = func(c int) bool { return c <= 0 }
+	case ">":
+		accept = func(c int) bool { return c > 0 }
+	case ">=":
+		accept = func(c int) bool { return c >= 0 }
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op.text)
+	}
+	return func(record map[string]interface{}) bool {
+		fieldValue := lookup(record, field)
+		if value == nil || fieldValue == nil {
+			// Null only equals null, and is neither less nor greater than anything.
+			equal := value == nil && fieldValue == nil
+			return equal && op.text == "=" || !equal && (op.text == "!=" || op.text == "<>")
+		}
+		c, ok := compare(fieldValue, value)
+		return ok && accept(c)
+	}, nil
+}
+
+func (p *parser) parseValueList() ([]interface{}, error) {
+	if tok := p.next(); tok.kind != tokenLParen {
+		return nil, fmt.Errorf("expected (, found %q", tok.text)
+	}
+	var values []interface{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		switch tok := p.next(); tok.kind {
+		case tokenComma:
+		case tokenRParen:
+			return values, nil
+		default:
+			return nil, fmt.Errorf("expected , or ), found %q", tok.text)
+		}
+	}
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenString, tok.kind == tokenDate:
+		return tok.text, nil
+	case tok.kind == tokenNumber:
+		return strconv.ParseFloat(tok.text, 64)
+	case tok.is("TRUE"):
+		return true, nil
+	case tok.is("FALSE"):
+		return false, nil
+	case tok.is("NULL"):
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", tok.text)
+}
+
+// likePattern converts a LIKE pattern to a regular expression. The wildcards % and _ match literally when escaped with
+// a backslash.
+func likePattern(like string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range like {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return sb.String()
+}
+
+// lookup returns the value of a field of a record, ignoring the case of the field name, and following relationships
+// for field names containing dots.
+func lookup(record map[string]interface{}, field string) interface{} {
+	var value interface{} = record
+	for _, name := range strings.Split(field, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = lookupField(m, name)
+	}
+	return value
+}
+
+func lookupField(record map[string]interface{}, name string) interface{} {
+	if value, ok := record[name]; ok {
+		return value
+	}
+	for key, value := range record {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return nil
+}
+
+// compare compares two non-null values. Strings compare case-insensitively, like in SOQL. It returns false if the
+// values are not comparable.
+func compare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, a == nil && b == nil
+	}
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(strings.ToLower(x), strings.ToLower(y)), true
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x == y:
+			return 0, true
+		case !x:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case fmt.Stringer:
+		// json.Number
+		f, err := strconv.ParseFloat(n.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// execute returns the records matching the query, sorted, offset and limited.
+func (q *query) execute(records []map[string]interface{}) []map[string]interface{} {
+	var matched []map[string]interface{}
+	for _, record := range records {
+		if q.where == nil || q.where(record) {
+			matched = append(matched, record)
+		}
+	}
+
+	if len(q.orderBy) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			for _, order := range q.orderBy {
+				a, b := lookup(matched[i], order.field), lookup(matched[j], order.field)
+				if a == nil || b == nil {
+					if (a == nil) == (b == nil) {
+						continue
+					}
+					return (a == nil) != order.nullsLast
+				}
+				c, ok := compare(a, b)
+				if !ok || c == 0 {
+					continue
+				}
+				return (c < 0) != order.descending
+			}
+			return false
+		})
+	}
+
+	if q.offset >= len(matched) {
+		return nil
+	}
+	matched = matched[q.offset:]
+	if q.limit >= 0 && q.limit < len(matched) {
+		matched = matched[:q.limit]
+	}
+	return matched
+}
@@ -0,0 +1,88 @@
+package simpleforcetest
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	records := []map[string]interface{}{
+		{"Id": "1", "Name": "Acme", "Amount": 100.0, "Active": true, "CloseDate": "2024-03-01"},
+		{"Id": "2", "Name": "Globex", "Amount": 50.0, "Active": false, "CloseDate": "2024-01-15"},
+		{"Id": "3", "Name": "initech", "Amount": nil, "Active": true, "CloseDate": nil},
+		{"Id": "4", "Name": "Acme Europe", "Amount": 75.5, "Active": false, "CloseDate": "2024-02-01"},
+	}
+
+	for soql, expected := range map[string]string{
+		"SELECT Id FROM Account":                                                  "[1 2 3 4]",
+		"SELECT Id FROM Account WHERE Name = 'acme'":                              "[1]",
+		"select Id from Account where Name like 'Acme%' order by Amount":          "[4 1]",
+		"SELECT Id FROM Account WHERE Amount > 60 AND Active = true":              "[1]",
+		"SELECT Id FROM Account WHERE Amount = null OR NOT (Amount >= 75.5)":      "[2 3]",
+		"SELECT Id FROM Account WHERE Name IN ('Globex', 'Initech')":              "[2 3]",
+		"SELECT Id FROM Account WHERE Name NOT IN ('Globex') AND Amount != null":  "[1 4]",
+		"SELECT Id FROM Account WHERE CloseDate < 2024-02-15":                     "[2 4]",
+		"SELECT Id FROM Account ORDER BY Amount DESC NULLS LAST LIMIT 3":          "[1 4 2]",
+		"SELECT Id FROM Account ORDER BY Amount LIMIT 2 OFFSET 1":                 "[2 4]",
+		"SELECT Id FROM Account ORDER BY Active DESC, Name DESC":                  "[3 1 2 4]",
+		"SELECT Id FROM Account WHERE Active = false OR Amount > 90 AND Id = '1'": "[1 2 4]",
+	} {
+		q, err := parseQuery(soql)
+		if err != nil {
+			t.Errorf("%s: %v", soql, err)
+			continue
+		}
+		var ids []interface{}
+		for _, record := range q.execute(records) {
+			ids = append(ids, record["Id"])
+		}
+		if fmt.Sprint(ids) != expected {
+			t.Errorf("%s: expected %s, got %v", soql, expected, ids)
+		}
+	}
+
+	for _, soql := range []string{
+		"SELECT FROM Account",
+		"SELECT Id FROM Account WHERE",
+		"SELECT Id FROM Account WHERE Name = 'unterminated",
+		"SELECT Id FROM Account LIMIT -1",
+		"SELECT Id FROM Account WHERE CreatedDate = TODAY",
+		"SELECT Id FROM Account GROUP BY Name",
+	} {
+		if _, err := parseQuery(soql); err == nil {
+			t.Errorf("%s: expected an error", soql)
+		}
+	}
+}
+
+func TestParseQuery_LikeEscapes(t *testing.T) {
+	records := []map[string]interface{}{
+		{"Id": "1", "Name": "a%"},
+		{"Id": "2", "Name": "ab"},
+		{"Id": "3", "Name": "a_c"},
+		{"Id": "4", "Name": "abc"},
+		{"Id": "5", "Name": `a\b`},
+	}
+
+	for soql, expected := range map[string]string{
+		`SELECT Id FROM Account WHERE Name LIKE 'a%'`:     "[1 2 3 4 5]",
+		`SELECT Id FROM Account WHERE Name LIKE 'a\%'`:    "[1]",
+		`SELECT Id FROM Account WHERE Name LIKE 'a_c'`:    "[3 4]",
+		`SELECT Id FROM Account WHERE Name LIKE 'a\_c'`:   "[3]",
+		`SELECT Id FROM Account WHERE Name LIKE 'a\\\\%'`: "[5]",
+		`SELECT Id FROM Account WHERE Name = 'a\%'`:       "[1]",
+	} {
+		q, err := parseQuery(soql)
+		if err != nil {
+			t.Errorf("%s: %v", soql, err)
+			continue
+		}
+		var ids []interface{}
+		for _, record := range q.execute(records) {
+			ids = append(ids, record["Id"])
+		}
+		if fmt.Sprint(ids) != expected {
+			t.Errorf("%s: expected %s, got %v", soql, expected, ids)
+		}
+	}
+}
@@ -3,15 +3,24 @@ package simpleforce
 import (
 	"bytes"
 	"encoding/json"
-	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 const (
 	sobjectClientKey              = "__client__" // private attribute added to locate client instance.
+	sobjectErrorKey               = "__error__"  // private attribute holding the error of the last failed operation.
+	sobjectETagKey                = "__etag__"   // private attributes holding the ETag and Last-Modified headers of Get.
+	sobjectLastModifiedKey        = "__lastModified__"
+	sobjectIfMatchKey             = "__ifMatch__" // private attributes holding the preconditions of the next request.
+	sobjectIfUnmodifiedSinceKey   = "__ifUnmodifiedSince__"
+	sobjectDuplicateRuleHeaderKey = "__duplicateRuleHeader__" // private attribute holding the duplicate rule header of the next save.
+	sobjectCallOptionsKey         = "__callOptions__"         // private attribute holding the call options of the next request.
+	sobjectPrivateKeyPrefix       = "__"
 	sobjectAttributesKey          = "attributes" // points to the attributes structure which should be common to all SObjects.
 	sobjectIDKey                  = "Id"
 	sobjectExternalIDFieldNameKey = "ExternalIDField"
@@ -60,14 +69,14 @@ func (obj *SObject) Describe() *SObjectMeta {
 		// Sanity check.
 		return nil
 	}
-	url := obj.client().makeURL("sobjects/" + obj.Type() + "/describe")
-	data, err := obj.client().httpRequest(http.MethodGet, url, nil)
+	url := obj.client().makeURL(obj.sobjectsPath("describe"))
+	data, err := obj.client().httpRequest(obj.operation("Describe"), http.MethodGet, url, nil)
 	if err != nil {
 		return nil
 	}
 
 	var meta SObjectMeta
-	err = json.Unmarshal(data, &meta)
+	err = obj.client().unmarshal(data, &meta)
 	if err != nil {
 		return nil
 	}
@@ -80,33 +89,68 @@ func (obj *SObject) Describe() *SObjectMeta {
 // If query is successful, the SObject is updated in-place and exact same address is returned; otherwise, nil is
 // returned if failed.
 func (obj *SObject) Get(id ...string) *SObject {
+	if len(id) > 0 {
+		return obj.GetFields(id[0])
+	}
+	return obj.GetFields("")
+}
+
+// GetFields works like Get, but only retrieves the provided fields of the SObject. All fields are retrieved if fields
+// is empty. If id is empty, the existing ID of the SObject is used.
+func (obj *SObject) GetFields(id string, fields ...string) *SObject {
 	if obj.Type() == "" || obj.client() == nil {
 		// Sanity check.
 		return nil
 	}
 
 	oid := obj.ID()
-	if len(id) > 0 {
-		oid = id[0]
+	if id != "" {
+		oid = id
 	}
 	if oid == "" {
-		log.Println(logPrefix, "object id not found.")
+		obj.client().logln("object id not found.")
 		return nil
 	}
 
-	url := obj.client().makeURL("sobjects/" + obj.Type() + "/" + oid)
-	data, err := obj.client().httpRequest(http.MethodGet, url, nil)
+	return obj.get(obj.sobjectsPath(oid), fields)
+}
+
+// GetByExternalID retrieves the SObject whose external ID field has the provided value. If fields are provided, only
+// those fields are retrieved. The SObject is updated in-place and the same address is returned; nil is returned if
+// the record doesn't exist or the request failed.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/dome_upsert.htm
+func (obj *SObject) GetByExternalID(field, value string, fields ...string) *SObject {
+	if obj.Type() == "" || obj.client() == nil || field == "" || value == "" {
+		// Sanity check.
+		return nil
+	}
+
+	return obj.get(obj.sobjectsPath(field+"/"+url.PathEscape(value)), fields)
+}
+
+// get retrieves the record at the REST API path and decodes it into the SObject.
+func (obj *SObject) get(path string, fields []string) *SObject {
+	if len(fields) > 0 {
+		path += "?fields=" + url.QueryEscape(strings.Join(fields, ","))
+	}
+
+	url := obj.client().makeURL(path)
+	data, header, err := obj.client().httpRequestHeader(obj.operation("Get"), http.MethodGet, url, nil, obj.requestHeader(nil))
+	obj.setErr(err)
 	if err != nil {
-		log.Println(logPrefix, "http request failed,", err)
+		obj.client().logln("http request failed,", err)
 		return nil
 	}
 
-	err = json.Unmarshal(data, obj)
+	err = obj.client().unmarshal(data, obj)
+	obj.setErr(err)
 	if err != nil {
-		log.Println(logPrefix, "json decode failed,", err)
+		obj.client().logln("json decode failed,", err)
 		return nil
 	}
 
+	obj.setPrivate(sobjectETagKey, header.Get("ETag"))
+	obj.setPrivate(sobjectLastModifiedKey, header.Get("Last-Modified"))
 	return obj
 }
 
@@ -124,20 +168,23 @@ func (obj *SObject) Create() *SObject {
 	reqObj := obj.makeCopy()
 	reqData, err := json.Marshal(reqObj)
 	if err != nil {
-		log.Println(logPrefix, "failed to convert sobject to json,", err)
+		obj.client().logln("failed to convert sobject to json,", err)
 		return nil
 	}
 
-	url := obj.client().makeURL("sobjects/" + obj.Type() + "/")
-	respData, err := obj.client().httpRequest(http.MethodPost, url, bytes.NewReader(reqData))
+	url := obj.client().makeURL(obj.sobjectsPath(""))
+	header := obj.addDuplicateRuleHeader(obj.requestHeader(nil))
+	respData, _, err := obj.client().httpRequestHeader(obj.operation("Create"), http.MethodPost, url, bytes.NewReader(reqData), header)
+	obj.setErr(err)
 	if err != nil {
-		log.Println(logPrefix, "failed to process http request,", err)
+		obj.client().logln("failed to process http request,", err)
 		return nil
 	}
 
 	err = obj.setIDFromResponseData(respData)
+	obj.setErr(err)
 	if err != nil {
-		log.Println(logPrefix, "failed to parse response,", err)
+		obj.client().logln("failed to parse response,", err)
 		return nil
 	}
 
@@ -145,7 +192,8 @@ func (obj *SObject) Create() *SObject {
 }
 
 // Update updates SObject in place. Upon successful, same SObject is returned for chained access.
-// ID is required.
+// ID is required. If a precondition has been set with IfMatch or IfUnmodifiedSince and the record has been modified
+// since, nil is returned and Err matches ErrConflict.
 func (obj *SObject) Update() *SObject {
 	if obj.Type() == "" || obj.client() == nil || obj.ID() == "" {
 		// Sanity check.
@@ -156,21 +204,19 @@ func (obj *SObject) Update() *SObject {
 	reqObj := obj.makeCopy()
 	reqData, err := json.Marshal(reqObj)
 	if err != nil {
-		log.Println(logPrefix, "failed to convert sobject to json,", err)
+		obj.client().logln("failed to convert sobject to json,", err)
 		return nil
 	}
 
-	queryBase := "sobjects/"
-	if obj.client().useToolingAPI {
-		queryBase = "tooling/sobjects/"
-	}
-	url := obj.client().makeURL(queryBase + obj.Type() + "/" + obj.ID())
-	respData, err := obj.client().httpRequest(http.MethodPatch, url, bytes.NewReader(reqData))
+	url := obj.client().makeURL(obj.sobjectsPath(obj.ID()))
+	header := obj.addDuplicateRuleHeader(obj.requestHeader(obj.preconditionHeader()))
+	respData, _, err := obj.client().httpRequestHeader(obj.operation("Update"), http.MethodPatch, url, bytes.NewReader(reqData), header)
+	obj.setErr(err)
 	if err != nil {
-		log.Println(logPrefix, "failed to process http request,", err)
+		obj.client().logln("failed to process http request,", err)
 		return nil
 	}
-	log.Println(string(respData))
+	obj.client().logln(string(respData))
 
 	return obj
 }
@@ -178,12 +224,12 @@ func (obj *SObject) Update() *SObject {
 // Upsert creates SObject or updates existing SObject in place. Upon successful upsert, same SObject is returned for chained access.
 // ID, ExternalIDField and Type are required. ID is the value of the external ID in this case.
 func (obj *SObject) Upsert() *SObject {
-	log.Println(logPrefix, "ExternalID:", obj.ExternalID())
-	log.Println(logPrefix, "ExternalIDField:", obj.ExternalIDFieldName())
+	obj.client().logln("ExternalID:", obj.ExternalID())
+	obj.client().logln("ExternalIDField:", obj.ExternalIDFieldName())
 	if obj.Type() == "" || obj.client() == nil || obj.ExternalIDFieldName() == "" ||
 		obj.ExternalID() == "" {
 		// Sanity check.
-		log.Println(logPrefix, "required fields are missing")
+		obj.client().logln("required fields are missing")
 		return nil
 	}
 
@@ -191,19 +237,16 @@ func (obj *SObject) Upsert() *SObject {
 	reqObj := obj.makeCopy()
 	reqData, err := json.Marshal(reqObj)
 	if err != nil {
-		log.Println(logPrefix, "failed to convert sobject to json,", err)
+		obj.client().logln("failed to convert sobject to json,", err)
 		return nil
 	}
 
-	queryBase := "sobjects/"
-	if obj.client().useToolingAPI {
-		queryBase = "tooling/sobjects/"
-	}
-	url := obj.client().
-		makeURL(queryBase + obj.Type() + "/" + obj.ExternalIDFieldName() + "/" + obj.ExternalID())
-	respData, err := obj.client().httpRequest(http.MethodPatch, url, bytes.NewReader(reqData))
+	url := obj.client().makeURL(obj.sobjectsPath(obj.ExternalIDFieldName() + "/" + obj.ExternalID()))
+	header := obj.addDuplicateRuleHeader(obj.requestHeader(nil))
+	respData, _, err := obj.client().httpRequestHeader(obj.operation("Upsert"), http.MethodPatch, url, bytes.NewReader(reqData), header)
+	obj.setErr(err)
 	if err != nil {
-		log.Println(logPrefix, "failed to process http request,", err)
+		obj.client().logln("failed to process http request,", err)
 		return nil
 	}
 
@@ -211,8 +254,9 @@ func (obj *SObject) Upsert() *SObject {
 	// a 204 with an empty response
 	if len(respData) > 0 {
 		err = obj.setIDFromResponseData(respData)
+		obj.setErr(err)
 		if err != nil {
-			log.Println(logPrefix, "failed to parse response,", err)
+			obj.client().logln("failed to parse response,", err)
 			return nil
 		}
 	}
@@ -221,7 +265,8 @@ func (obj *SObject) Upsert() *SObject {
 }
 
 // Delete deletes an SObject record identified by external ID. nil is returned if the operation completes successfully;
-// otherwise an error is returned
+// otherwise an error is returned. Use errors.Is with ErrNotFound or ErrRowLocked to tell common failures apart, and
+// ErrConflict if a precondition has been set with IfMatch or IfUnmodifiedSince.
 func (obj *SObject) Delete(id ...string) error {
 	if obj.Type() == "" || obj.client() == nil {
 		// Sanity check
@@ -236,9 +281,25 @@ func (obj *SObject) Delete(id ...string) error {
 		return ErrFailure
 	}
 
-	url := obj.client().makeURL("sobjects/" + obj.Type() + "/" + obj.ID())
-	log.Println(url)
-	_, err := obj.client().httpRequest(http.MethodDelete, url, nil)
+	return obj.delete(obj.sobjectsPath(oid))
+}
+
+// DeleteByExternalID deletes the SObject record whose external ID field has the provided value. Errors are the same
+// as for Delete.
+func (obj *SObject) DeleteByExternalID(field, value string) error {
+	if obj.Type() == "" || obj.client() == nil || field == "" || value == "" {
+		// Sanity check
+		return ErrFailure
+	}
+
+	return obj.delete(obj.sobjectsPath(field + "/" + url.PathEscape(value)))
+}
+
+// delete deletes the record at the REST API path.
+func (obj *SObject) delete(path string) error {
+	url := obj.client().makeURL(path)
+	obj.client().logln(url)
+	_, _, err := obj.client().httpRequestHeader(obj.operation("Delete"), http.MethodDelete, url, nil, obj.requestHeader(obj.preconditionHeader()))
 	if err != nil {
 		return err
 	}
@@ -246,6 +307,45 @@ func (obj *SObject) Delete(id ...string) error {
 	return nil
 }
 
+// Err returns the error of the last Get, Create, Update or Upsert of the SObject, or nil if it succeeded. This allows
+// the cause to be inspected when one of the chained methods returns nil.
+func (obj *SObject) Err() error {
+	err, _ := obj.InterfaceField(sobjectErrorKey).(error)
+	return err
+}
+
+// ETag returns the ETag header returned by the last Get of the SObject, or an empty string if there was none.
+func (obj *SObject) ETag() string {
+	return obj.StringField(sobjectETagKey)
+}
+
+// LastModified returns the Last-Modified header returned by the last Get of the SObject, or the zero time if there was
+// none.
+func (obj *SObject) LastModified() time.Time {
+	t, _ := http.ParseTime(obj.StringField(sobjectLastModifiedKey))
+	return t
+}
+
+// IfMatch makes the next Update or Delete of the SObject conditional on the record still having the provided ETag,
+// typically the one returned by ETag. The same SObject pointer is returned to allow chained access.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_ifmatch.htm
+func (obj *SObject) IfMatch(etag string) *SObject {
+	obj.setPrivate(sobjectIfMatchKey, etag)
+	return obj
+}
+
+// IfUnmodifiedSince makes the next Update or Delete of the SObject conditional on the record not having been modified
+// since t, typically the time returned by LastModified. The same SObject pointer is returned to allow chained access.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/headers_ifunmodifiedsince.htm
+func (obj *SObject) IfUnmodifiedSince(t time.Time) *SObject {
+	if t.IsZero() {
+		obj.setPrivate(sobjectIfUnmodifiedSinceKey, "")
+	} else {
+		obj.setPrivate(sobjectIfUnmodifiedSinceKey, t.UTC().Format(http.TimeFormat))
+	}
+	return obj
+}
+
 // Type returns the type, or sometimes referred to as name, of an SObject.
 func (obj *SObject) Type() string {
 	attributes := obj.AttributesField()
@@ -317,7 +417,7 @@ func (obj *SObject) SObjectField(typeName, key string) *SObject {
 	rIndex := strings.LastIndex(url, "/")
 	if rIndex == -1 || rIndex+1 == len(url) {
 		// hmm... this shouldn't happen, unless the URL is hand crafted.
-		log.Println(logPrefix, "invalid url,", url)
+		obj.client().logln("invalid url,", url)
 		return nil
 	}
 	oid = url[rIndex+1:]
@@ -384,6 +484,54 @@ func (obj *SObject) setClient(client *Client) {
 	(*obj)[sobjectClientKey] = client
 }
 
+// operation identifies a request made on behalf of the SObject for middleware.
+func (obj *SObject) operation(name string) operation {
+	return operation{name: name, sobjectType: obj.Type()}
+}
+
+// sobjectsPath returns the API path of a resource of the SObject's type, under "tooling/" if the client is a Tooling
+// view.
+func (obj *SObject) sobjectsPath(resource string) string {
+	base := "sobjects/"
+	if obj.client().useToolingAPI {
+		base = "tooling/sobjects/"
+	}
+	return base + obj.Type() + "/" + resource
+}
+
+// setErr records the error of the last operation on the SObject.
+func (obj *SObject) setErr(err error) {
+	if err == nil {
+		delete(*obj, sobjectErrorKey)
+		return
+	}
+	(*obj)[sobjectErrorKey] = err
+}
+
+// setPrivate sets or, if value is empty, removes a private attribute of the SObject.
+func (obj *SObject) setPrivate(key, value string) {
+	if value == "" {
+		delete(*obj, key)
+		return
+	}
+	(*obj)[key] = value
+}
+
+// preconditionHeader returns the conditional request headers set by IfMatch and IfUnmodifiedSince. The preconditions
+// are cleared, as they only apply to a single request.
+func (obj *SObject) preconditionHeader() http.Header {
+	header := http.Header{}
+	if etag := obj.StringField(sobjectIfMatchKey); etag != "" {
+		header.Set("If-Match", etag)
+	}
+	if since := obj.StringField(sobjectIfUnmodifiedSinceKey); since != "" {
+		header.Set("If-Unmodified-Since", since)
+	}
+	obj.setPrivate(sobjectIfMatchKey, "")
+	obj.setPrivate(sobjectIfUnmodifiedSinceKey, "")
+	return header
+}
+
 // setType sets the type, or name for the SObject.
 func (obj *SObject) setType(typeName string) {
 	attributes := obj.InterfaceField(sobjectAttributesKey)
@@ -408,13 +556,20 @@ func (obj *SObject) setID(id string) {
 func (obj *SObject) makeCopy() map[string]interface{} {
 	stripped := make(map[string]interface{})
 	for key, val := range *obj {
-		if key == sobjectClientKey ||
+		if strings.HasPrefix(key, sobjectPrivateKeyPrefix) ||
 			key == sobjectAttributesKey ||
 			key == sobjectIDKey ||
 			key == sobjectExternalIDFieldNameKey ||
 			key == obj.ExternalIDFieldName() {
 			continue
 		}
+		// Compound fields are read-only and have to be written through their component fields.
+		if components, ok := compoundComponents(key, val); ok {
+			for k, v := range components {
+				stripped[k] = v
+			}
+			continue
+		}
 		stripped[key] = val
 	}
 	for _, key := range blacklistedUpdateFields {
@@ -432,12 +587,12 @@ func (obj *SObject) setIDFromResponseData(respData []byte) error {
 	}
 	err := json.Unmarshal(respData, &respVal)
 	if err != nil {
-		log.Println(logPrefix, "failed to process response data,", err)
+		obj.client().logln("failed to process response data,", err)
 		return err
 	}
 
 	if !respVal.Success || respVal.ID == "" {
-		log.Println(logPrefix, "unsuccessful")
+		obj.client().logln("unsuccessful")
 		return errors.New("request was unsuccessful")
 	}
 
@@ -1,11 +1,14 @@
 package simpleforce
 
 import (
+	"fmt"
 	"log"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 func TestSObject_AttributesField(t *testing.T) {
@@ -133,6 +136,28 @@ func TestSObject_Get(t *testing.T) {
 	}
 }
 
+func TestSObject_GetByExternalID(t *testing.T) {
+	var requestURI string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.URL.RequestURI()
+		fmt.Fprint(w, `{"attributes":{"type":"Account"},"Id":"001000000000001","Name":"Acme"}`)
+	})
+
+	obj := client.SObject("Account").GetByExternalID("ERP_Key__c", "A/100", "Id", "Name")
+	if obj == nil || obj.ID() != "001000000000001" || obj.StringField("Name") != "Acme" {
+		t.Fatalf("unexpected object %v", obj)
+	}
+	expected := "/services/data/v" + DefaultAPIVersion + "/sobjects/Account/ERP_Key__c/A%2F100?fields=Id%2CName"
+	if requestURI != expected {
+		t.Errorf("expected request to %s, got %s", expected, requestURI)
+	}
+
+	// Negative: missing external ID value.
+	if client.SObject("Account").GetByExternalID("ERP_Key__c", "") != nil {
+		t.Fail()
+	}
+}
+
 func TestSObject_Create(t *testing.T) {
 	client := requireClient(t, true)
 
@@ -288,6 +313,77 @@ func TestSObject_Delete(t *testing.T) {
 	}
 }
 
+func TestSObject_DeleteWithID(t *testing.T) {
+	var requestPath string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.EscapedPath()
+		switch requestPath {
+		case "/services/data/v" + DefaultAPIVersion + "/sobjects/Case/500000000000002":
+			w.WriteHeader(http.StatusNoContent)
+		case "/services/data/v" + DefaultAPIVersion + "/sobjects/Case/Ticket__c/T-1":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"message":"unable to obtain exclusive access to this record","errorCode":"UNABLE_TO_LOCK_ROW"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `[{"message":"The requested resource does not exist","errorCode":"NOT_FOUND"}]`)
+		}
+	})
+
+	// The passed ID takes precedence over the ID of the SObject.
+	obj := client.SObject("Case")
+	obj.setID("500000000000001")
+	if err := obj.Delete("500000000000002"); err != nil {
+		t.Errorf("unexpected error %v, request path %s", err, requestPath)
+	}
+
+	if err := client.SObject("Case").Delete("500000000000003"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := client.SObject("Case").DeleteByExternalID("Ticket__c", "T-1"); !errors.Is(err, ErrRowLocked) {
+		t.Errorf("expected ErrRowLocked, got %v", err)
+	}
+	if err := client.SObject("Case").DeleteByExternalID("Ticket__c", ""); err != ErrFailure {
+		t.Errorf("expected ErrFailure, got %v", err)
+	}
+}
+
+func TestSObject_ConditionalUpdate(t *testing.T) {
+	lastModified := "Fri, 29 Apr 2022 10:15:30 GMT"
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Last-Modified", lastModified)
+			fmt.Fprint(w, `{"attributes":{"type":"Account"},"Id":"001000000000001","Name":"Acme"}`)
+		case http.MethodPatch, http.MethodDelete:
+			if r.Header.Get("If-Match") != `"v1"` && r.Header.Get("If-Unmodified-Since") != lastModified {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprint(w, `[{"message":"The requested resource has been modified","errorCode":"PRECONDITION_FAILED"}]`)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	obj := client.SObject("Account").Get("001000000000001")
+	if obj == nil || obj.ETag() != `"v1"` || obj.LastModified().Format(http.TimeFormat) != lastModified {
+		t.Fatalf("unexpected object %v", obj)
+	}
+
+	if obj.IfMatch(obj.ETag()).Set("Name", "Acme Corp").Update() == nil {
+		t.Errorf("unexpected error %v", obj.Err())
+	}
+	if obj.IfMatch(`"v0"`).Update() != nil || !errors.Is(obj.Err(), ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", obj.Err())
+	}
+	if err := obj.IfUnmodifiedSince(time.Now()).Delete(); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if err := obj.IfUnmodifiedSince(obj.LastModified()).Delete(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 // TestSObject_GetUpdate validates updating of existing records.
 func TestSObject_GetUpdate(t *testing.T) {
 	client := requireClient(t, true)
@@ -3,7 +3,6 @@ package simpleforce
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/url"
 )
 
@@ -19,11 +18,18 @@ type ExecuteAnonymousResult struct {
 }
 
 // Tooling is called to specify Tooling API, e.g. client.Tooling().Query(q)
+// It returns a lightweight view of the client which shares its session, so the client itself keeps using the REST API
+// and both can be used concurrently. SObjects created through the view use the Tooling API as well, for reads, writes,
+// deletes and Describe.
 func (client *Client) Tooling() *Client {
-	client.useToolingAPI = true
-	return client
+	tooling := *client
+	tooling.useToolingAPI = true
+	return &tooling
 }
 
+// UnTooling switches a view returned by Tooling back to the REST API.
+//
+// Deprecated: Tooling no longer changes the client it is called on, so there is nothing to undo.
 func (client *Client) UnTooling() {
 	client.useToolingAPI = false
 }
@@ -36,12 +42,12 @@ func (client *Client) ExecuteAnonymous(apexBody string) (*ExecuteAnonymousResult
 
 	// Create the endpoint
 	formatString := "%s/services/data/v%s/tooling/executeAnonymous/?anonymousBody=%s"
-	baseURL := client.instanceURL
+	baseURL := client.instanceURL()
 	endpoint := fmt.Sprintf(formatString, baseURL, client.apiVersion, url.QueryEscape(apexBody))
 
-	data, err := client.httpRequest("GET", endpoint, nil)
+	data, err := client.httpRequest(operation{name: "ExecuteAnonymous"}, "GET", endpoint, nil)
 	if err != nil {
-		log.Println(logPrefix, "HTTP GET request failed:", endpoint)
+		client.logln("HTTP GET request failed:", endpoint)
 		return nil, err
 	}
 
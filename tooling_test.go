@@ -1,6 +1,10 @@
 package simpleforce
 
 import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -32,3 +36,77 @@ func TestClient_ExecuteAnonymous(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestClient_ToolingView(t *testing.T) {
+	var mu sync.Mutex
+	paths := make(map[string]int)
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path]++
+		mu.Unlock()
+		fmt.Fprint(w, `{"totalSize":0,"done":true,"records":[]}`)
+	})
+	tooling := client.Tooling()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.Query("SELECT Id FROM Account")
+		}()
+		go func() {
+			defer wg.Done()
+			tooling.Query("SELECT Id FROM Layout")
+		}()
+	}
+	wg.Wait()
+
+	if paths["/services/data/v"+DefaultAPIVersion+"/query"] != 10 ||
+		paths["/services/data/v"+DefaultAPIVersion+"/tooling/query"] != 10 {
+		t.Errorf("unexpected requests %v", paths)
+	}
+
+	// The view shares the session of the client.
+	client.SetSidLoc("__NEW_SESSION_ID__", client.GetLoc())
+	if tooling.GetSid() != "__NEW_SESSION_ID__" {
+		t.Errorf("expected tooling view to share the session")
+	}
+}
+
+func TestClient_ToolingView_SObject(t *testing.T) {
+	var paths []string
+	client := requireTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			fmt.Fprint(w, `{"id":"01p000000000001","success":true,"errors":[]}`)
+		default:
+			fmt.Fprint(w, `{"attributes":{"type":"ApexClass"},"Id":"01p000000000001","Name":"Foo"}`)
+		}
+	})
+	tooling := client.Tooling()
+
+	obj := tooling.SObject("ApexClass").Set("Name", "Foo").Create()
+	if obj == nil {
+		t.Fatal("expected create to succeed")
+	}
+	if tooling.SObject("ApexClass").Get(obj.ID()) == nil {
+		t.Fatal("expected get to succeed")
+	}
+	if err := tooling.SObject("ApexClass").Delete(obj.ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	base := "/services/data/v" + DefaultAPIVersion + "/tooling/sobjects/ApexClass/"
+	expected := []string{
+		"POST " + base,
+		"GET " + base + "01p000000000001",
+		"DELETE " + base + "01p000000000001",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}